@@ -0,0 +1,83 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/tools"
+)
+
+// readImagesFile reads gadget images from a file containing either a YAML list or one image per line. Empty lines
+// and lines starting with '#' are ignored.
+func readImagesFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading gadget images file: %w", err)
+	}
+
+	var images []string
+	if err := yaml.Unmarshal(data, &images); err != nil {
+		images = nil
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			images = append(images, line)
+		}
+	}
+
+	for i, img := range images {
+		images[i] = tools.NormalizeImageRef(img)
+		if !tools.IsValidImageRef(images[i]) {
+			return nil, fmt.Errorf("invalid gadget image %q in %s", img, path)
+		}
+	}
+	return images, nil
+}
+
+// mergeImages returns the union of the given image lists, keeping the order of first appearance.
+func mergeImages(lists ...[]string) []string {
+	var images []string
+	for _, list := range lists {
+		for _, img := range list {
+			img = tools.NormalizeImageRef(img)
+			if img == "" || slices.Contains(images, img) {
+				continue
+			}
+			images = append(images, img)
+		}
+	}
+	return images
+}
+
+// parseVersionPins parses a comma-separated list of gadget=version pairs e.g. 'trace_dns=v0.40.0,trace_open=v0.40.0'.
+func parseVersionPins(s string) (map[string]string, error) {
+	pins := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		name, version, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" || version == "" {
+			return nil, fmt.Errorf("invalid gadget version pin %q, expected gadget=version", pair)
+		}
+		pins[name] = version
+	}
+	return pins, nil
+}
@@ -23,35 +23,80 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/inspektor-gadget/ig-mcp-server/pkg/server"
 
 	"github.com/inspektor-gadget/ig-mcp-server/pkg/discoverer"
 	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/logging"
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/metrics"
 	"github.com/inspektor-gadget/ig-mcp-server/pkg/tools"
 )
 
 // This variable is used by the "version" command and is set during build
 var version = "undefined"
 
+// ociPasswordEnv is the environment variable with the password of -oci-username, kept out of the command line
+const ociPasswordEnv = "IG_MCP_OCI_PASSWORD"
+
 var (
 	// MCP server configuration
-	transport     = flag.String("transport", "stdio", fmt.Sprintf("transport to use (%s)", strings.Join(server.SupportedTransports, ", ")))
-	transportHost = flag.String("transport-host", "localhost", "host for the transport")
-	transportPort = flag.String("transport-port", "8080", "port for the transport")
+	transport       = flag.String("transport", "stdio", fmt.Sprintf("transport to use (%s)", strings.Join(server.SupportedTransports, ", ")))
+	transportHost   = flag.String("transport-host", "localhost", "host for the transport")
+	transportPort   = flag.String("transport-port", "8080", "port for the transport")
+	sseKeepAlive    = flag.Duration("sse-keepalive", 0, "interval for keep-alive messages on SSE connections, 0 disables them")
+	maxRequestBytes = flag.Int64("max-request-bytes", 0, "maximum size of request bodies for the sse and streamable-http transports, larger requests are rejected with 413, 0 disables the limit")
+	httpReadTimeout = flag.Duration("http-read-timeout", 0, "maximum duration for reading a request for the sse and streamable-http transports, also the maximum lifetime of their event streams, 0 means no timeout")
+	httpIdleTimeout = flag.Duration("http-idle-timeout", 0, "how long idle keep-alive connections of the sse and streamable-http transports are kept open between requests e.g. 2m, connections with an open event stream aren't idle, 0 means no timeout")
 	// Inspektor Gadget configuration
-	runtime                       = flag.String("runtime", "grpc-k8s", "runtime to use")
+	runtime                       = flag.String("runtime", gadgetmanager.RuntimeGrpcK8s, fmt.Sprintf("runtime to use (%s, %s)", gadgetmanager.RuntimeGrpcK8s, gadgetmanager.RuntimeGrpcLinux))
+	linuxSocket                   = flag.String("linux-socket", "", "absolute path of the unix socket of the ig daemon for the grpc-linux runtime, defaults to /var/run/ig/ig.socket")
 	gadgetImages                  = flag.String("gadget-images", "", "comma-separated list of gadget images to use (e.g. 'trace_dns:latest,trace_open:latest')")
-	gadgetDiscoverer              = flag.String("gadget-discoverer", "", "gadget discoverer to use (artifacthub)")
+	strictGadgetImages            = flag.Bool("strict-gadget-images", false, "fail on startup if any gadget image (or Artifact Hub package or OCI repository) is invalid or can't be resolved instead of skipping it")
+	gadgetImagesFile              = flag.String("gadget-images-file", "", "path to a file with gadget images to use, either a YAML list or one image per line (combined with -gadget-images)")
+	gadgetDiscoverer              = flag.String("gadget-discoverer", "", "gadget discoverer to use (artifacthub, oci)")
+	lazyGadgetTools               = flag.Bool("lazy-gadget-tools", false, "register gadget tools without getting their info on startup, the full tool is fetched on its first call")
+	maxGadgetTools                = flag.Int("max-gadget-tools", 0, "maximum number of gadget tools to register, in the order of the gadget images (discovered ones are sorted alphabetically), 0 means no limit")
+	maxBackgroundGadgets          = flag.Int("max-background-gadgets", 0, "maximum number of gadgets running in the background at the same time, 0 means no limit")
+	paramEnv                      = flag.String("param-env", "", "comma-separated list of environment variables gadget param values can reference as ${NAME}, e.g. for credentials")
+	globalFilter                  = flag.String("global-filter", "", "filter expression applied to every gadget run along with the filter of the call, e.g. 'k8s.namespace!=gadget'")
+	maxConcurrentRuns             = flag.Int("max-concurrent-runs", 0, "maximum number of foreground gadget runs at the same time, further runs wait briefly and fail as server busy, 0 means no limit")
+	defaultGadgetTimeout          = flag.Duration("default-gadget-timeout", 10*time.Second, "timeout for foreground gadget runs when none is given in the call, map gadgets fetch data every half of it")
+	mapFetchIntervalOverride      = flag.Bool("map-fetch-interval-override", true, "set map-fetch-interval to half of the timeout for foreground runs of map gadgets, disable to use the gadget's default")
+	igNamespace                   = flag.String("ig-namespace", "", "namespace where Inspektor Gadget is expected, searched first to detect if it's deployed before all namespaces")
+	igPodSelector                 = flag.String("ig-pod-selector", "k8s-app=gadget", "label selector of the Inspektor Gadget pods, used to detect if and where it is deployed")
+	gadgetInfoTimeout             = flag.Duration("gadget-info-timeout", 15*time.Second, "timeout to get the info of each gadget image on registration, images that time out are skipped")
+	gadgetInfoCacheTTL            = flag.Duration("gadget-info-cache-ttl", 0, "how long the info of a gadget image is reused before getting it again, 0 disables the cache")
+	warmGadgetInfoCache           = flag.Bool("warm-gadget-info-cache", false, "fetch the info of all gadget images in the background on startup so that the first call of each gadget tool is faster")
+	showAllFields                 = flag.Bool("show-all-fields", true, "include the fields gadgets mark as hidden (e.g. internal ones) in the results, disable to reduce their size")
+	nestedParams                  = flag.Bool("nested-params", false, "group gadget params by operator prefix in the tool schema instead of using a flat map of prefixed keys")
+	resultBudget                  = flag.Int("result-budget", 0, "number of result bytes returned to a session before results are truncated more aggressively, 0 disables it")
+	registerAfterDeploy           = flag.Bool("register-after-deploy", true, "register the gadget tools after deploying Inspektor Gadget, disable to only register them with the refresh-gadgets tool")
+	deployRetries                 = flag.Int("deploy-retries", 2, "number of retries for transient failures (e.g. registry timeouts) when deploying Inspektor Gadget")
+	chartURL                      = flag.String("chart-url", "", "URL of the Inspektor Gadget Helm chart to deploy, e.g. a mirror, the chart version is appended unless it's pinned with a tag or digest. Defaults to the official chart")
+	verifyGadgets                 = flag.String("verify-gadgets", "", "whether Inspektor Gadget deployed by this server verifies gadget image signatures (true, false), empty keeps the backend default")
+	templatesDir                  = flag.String("templates-dir", "", "directory with *.tmpl files overriding the tool description template (toolDescription.tmpl) or the one of a single gadget (<tool_name>.tmpl)")
 	artifactHubDiscovererOfficial = flag.Bool("artifacthub-official", false, "use only official gadgets from Artifact Hub")
+	gadgetVersionPins             = flag.String("gadget-version-pins", "", "comma-separated list of gadget=version pairs pinning discovered gadgets to a version (e.g. 'trace_dns=v0.40.0')")
+	ociRegistry                   = flag.String("oci-registry", "", "registry listed by the oci gadget discoverer, e.g. registry.example.com")
+	ociRepositoryPrefix           = flag.String("oci-repository-prefix", "", "only discover the repositories under this prefix of -oci-registry, e.g. team/gadgets")
+	ociUsername                   = flag.String("oci-username", "", "username for -oci-registry, the password is read from the "+ociPasswordEnv+" environment variable. Defaults to the Docker credentials")
+	artifactHubPreferredImage     = flag.String("artifacthub-preferred-image", "", "for Artifact Hub packages with multiple images, use the first one whose name or reference contains this value")
 	// Server configuration
-	logLevel    = flag.String("log-level", "", "log level (debug, info, warn, error)")
-	versionFlag = flag.Bool("version", false, "print version and exit")
+	logLevel       = flag.String("log-level", "", "log level (debug, info, warn, error)")
+	logFormat      = flag.String("log-format", "text", "log format (text, json)")
+	userAgent      = flag.String("user-agent", "ig-mcp-server/"+version, "user-agent for outbound HTTP requests")
+	metricsAddress = flag.String("metrics-address", "", "address to serve Prometheus metrics on under /metrics e.g. localhost:9090, empty disables them")
+	versionFlag    = flag.Bool("version", false, "print version and exit")
 )
 
-var log = slog.Default().With("component", "ig-mcp-server")
+var log = logging.Default().With("component", "ig-mcp-server")
 
 func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -59,52 +104,141 @@ func main() {
 
 	flag.Parse()
 
+	if err := setupLogging(*logFormat, *logLevel); err != nil {
+		logFatal("failed to set up logging", "error", err)
+	}
+
 	if *versionFlag {
-		log.Info("Inspektor Gadget MCP Server", "version", version)
+		log.Info("Inspektor Gadget MCP Server", versionAttrs()...)
 		os.Exit(0)
 	}
 
-	if *gadgetDiscoverer == "" && *gadgetImages == "" {
-		logFatal("either -gadget-images or -gadget-discoverer must be specified")
+	if *gadgetDiscoverer == "" && *gadgetImages == "" && *gadgetImagesFile == "" {
+		logFatal("either -gadget-images, -gadget-images-file or -gadget-discoverer must be specified")
 	}
 
-	if *logLevel != "" {
-		l, err := parseLogLevel(*logLevel)
-		if err != nil {
-			logFatal("invalid log level", "error", err)
-		}
-		slog.SetLogLoggerLevel(l)
+	if !slices.Contains(server.SupportedTransports, *transport) {
+		logFatal("unsupported transport", "transport", *transport, "supported", strings.Join(server.SupportedTransports, ", "))
+	}
+
+	if *defaultGadgetTimeout <= 0 {
+		logFatal("invalid default gadget timeout, it must be positive", "timeout", *defaultGadgetTimeout)
+	}
+
+	if *linuxSocket != "" && *runtime != gadgetmanager.RuntimeGrpcLinux {
+		logFatal("-linux-socket is only supported with the grpc-linux runtime", "runtime", *runtime)
+	}
+	if *linuxSocket != "" && !filepath.IsAbs(*linuxSocket) {
+		logFatal("invalid linux socket, the path must be absolute", "linux_socket", *linuxSocket)
+	}
+	if *maxGadgetTools < 0 {
+		logFatal("invalid max gadget tools, it must not be negative", "max_gadget_tools", *maxGadgetTools)
+	}
+	if *maxRequestBytes < 0 {
+		logFatal("invalid max request bytes, it must not be negative", "max_request_bytes", *maxRequestBytes)
+	}
+	if *httpReadTimeout < 0 || *httpIdleTimeout < 0 {
+		logFatal("invalid HTTP timeout, it must not be negative", "read_timeout", *httpReadTimeout, "idle_timeout", *httpIdleTimeout)
+	}
+	if *gadgetInfoTimeout <= 0 {
+		logFatal("invalid gadget info timeout, it must be positive", "timeout", *gadgetInfoTimeout)
+	}
+	if *gadgetInfoCacheTTL < 0 {
+		logFatal("invalid gadget info cache TTL, it must not be negative", "ttl", *gadgetInfoCacheTTL)
+	}
+	if *warmGadgetInfoCache && *gadgetInfoCacheTTL == 0 {
+		logFatal("-warm-gadget-info-cache requires -gadget-info-cache-ttl")
 	}
 
-	mgr, err := gadgetmanager.NewGadgetManager(*runtime)
+	mgrOpts := []gadgetmanager.Option{
+		gadgetmanager.WithMaxDetachedInstances(*maxBackgroundGadgets),
+		gadgetmanager.WithShowAllFields(*showAllFields),
+		gadgetmanager.WithInfoCacheTTL(*gadgetInfoCacheTTL),
+	}
+	if *linuxSocket != "" {
+		mgrOpts = append(mgrOpts, gadgetmanager.WithLinuxSocket(*linuxSocket))
+	}
+	mgr, err := gadgetmanager.NewGadgetManager(*runtime, mgrOpts...)
 	if err != nil {
 		logFatal("failed to create gadget manager", "error", err)
 	}
 	defer mgr.Close()
-	registry := tools.NewToolRegistry(mgr)
-
-	var images []string
-	if gadgetImages != nil && *gadgetImages != "" {
-		images = strings.Split(*gadgetImages, ",")
-	} else {
-		var opts []discoverer.Option
-		if *artifactHubDiscovererOfficial {
-			opts = append(opts, discoverer.WithArtifactHubOfficialOnly(true))
-		}
-		dis, err := discoverer.New(*gadgetDiscoverer, opts...)
-		if err != nil {
-			logFatal("failed to create gadget discoverer", "error", err)
+	descriptionTmpl, err := tools.ParseDescriptionTemplates(*templatesDir)
+	if err != nil {
+		logFatal("failed to parse tool description templates", "error", err)
+	}
+	envInfo := tools.EnvironmentInfo{Runtime: *runtime, Transport: *transport}
+	if *gadgetImages == "" && *gadgetImagesFile == "" {
+		envInfo.Discoverer = *gadgetDiscoverer
+	}
+	registryOpts := []tools.Option{
+		tools.WithUserAgent(*userAgent),
+		tools.WithDescriptionTemplates(descriptionTmpl),
+		tools.WithDefaultTimeout(*defaultGadgetTimeout),
+		tools.WithMapFetchIntervalOverride(*mapFetchIntervalOverride),
+		tools.WithStrictImages(*strictGadgetImages),
+		tools.WithResultBudget(*resultBudget),
+		tools.WithNestedParams(*nestedParams),
+		tools.WithInfoTimeout(*gadgetInfoTimeout),
+		tools.WithDeployRetries(*deployRetries),
+		tools.WithRegisterAfterDeploy(*registerAfterDeploy),
+		tools.WithEnvironmentInfo(envInfo),
+		tools.WithPodSelector(*igPodSelector),
+		tools.WithIGNamespace(*igNamespace),
+		tools.WithMaxGadgetTools(*maxGadgetTools),
+		tools.WithLazyGadgetTools(*lazyGadgetTools),
+		tools.WithInfoCache(*gadgetInfoCacheTTL > 0),
+		tools.WithMaxConcurrentRuns(*maxConcurrentRuns),
+		tools.WithGlobalFilter(*globalFilter),
+		tools.WithStreaming(*transport == server.SSETransport || *transport == server.StreamableHTTPTransport),
+	}
+	if *chartURL != "" {
+		registryOpts = append(registryOpts, tools.WithChartURL(*chartURL))
+	}
+	if *paramEnv != "" {
+		var names []string
+		for _, name := range strings.Split(*paramEnv, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
 		}
-		images, err = dis.ListImages()
+		registryOpts = append(registryOpts, tools.WithParamEnv(names))
+	}
+	if *verifyGadgets != "" {
+		verify, err := strconv.ParseBool(*verifyGadgets)
 		if err != nil {
-			logFatal("failed to list gadget images", "error", err)
+			logFatal("invalid value for -verify-gadgets", "error", err)
 		}
+		registryOpts = append(registryOpts, tools.WithVerifyGadgets(verify))
+	}
+	registry := tools.NewToolRegistry(mgr, registryOpts...)
+	if err := registry.SelfCheck(ctx); err != nil {
+		// Not fatal, Inspektor Gadget can still be deployed or started later
+		log.Error("Inspektor Gadget backend is not reachable, gadget runs will fail until this is fixed", "error", err)
 	}
 
-	srv := server.New(version, registry)
+	images, categories, err := listGadgetImages()
+	if err != nil {
+		logFatal("failed to list gadget images", "error", err)
+	}
+	registry.SetGadgetCategories(categories)
+
+	srv := server.New(version, registry,
+		server.WithSSEKeepAlive(*sseKeepAlive),
+		server.WithMaxRequestBytes(*maxRequestBytes),
+		server.WithReadTimeout(*httpReadTimeout),
+		server.WithIdleTimeout(*httpIdleTimeout),
+	)
 	if err = registry.Prepare(ctx, images); err != nil {
 		logFatal("failed to prepare tool registry", "error", err)
 	}
+	// Without lazy gadget tools, Prepare already got the info of every image and the cache is warm
+	if *warmGadgetInfoCache && *lazyGadgetTools {
+		go func() {
+			res := registry.WarmInfoCache(ctx)
+			log.Info("Warmed gadget info cache", "succeeded", res.Succeeded, "failed", res.Failed)
+		}()
+	}
 
 	go func() {
 		if err = srv.Start(*transport, *transportHost, *transportPort); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -112,6 +246,11 @@ func main() {
 		}
 	}()
 
+	go reloadOnHangup(ctx, registry)
+	if *metricsAddress != "" {
+		go serveMetrics(ctx, *metricsAddress)
+	}
+
 	<-ctx.Done()
 	log.Info("Received shutdown signal, shutting down server")
 	if err = srv.Shutdown(ctx); err != nil {
@@ -119,11 +258,135 @@ func main() {
 	}
 }
 
+// listGadgetImages returns the gadget images given with -gadget-images and -gadget-images-file, or the ones found by
+// the -gadget-discoverer along with their categories by image.
+func listGadgetImages() ([]string, map[string][]string, error) {
+	if *gadgetImages != "" || *gadgetImagesFile != "" {
+		var fileImages []string
+		if *gadgetImagesFile != "" {
+			var err error
+			fileImages, err = readImagesFile(*gadgetImagesFile)
+			if err != nil {
+				return nil, nil, fmt.Errorf("reading gadget images file: %w", err)
+			}
+		}
+		var inlineImages []string
+		if *gadgetImages != "" {
+			inlineImages = strings.Split(*gadgetImages, ",")
+		}
+		return mergeImages(inlineImages, fileImages), nil, nil
+	}
+
+	opts := []discoverer.Option{discoverer.WithUserAgent(*userAgent)}
+	if *artifactHubDiscovererOfficial {
+		opts = append(opts, discoverer.WithArtifactHubOfficialOnly(true))
+	}
+	if *gadgetVersionPins != "" {
+		pins, err := parseVersionPins(*gadgetVersionPins)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing gadget version pins: %w", err)
+		}
+		opts = append(opts, discoverer.WithVersionPins(pins))
+	}
+	if *strictGadgetImages {
+		opts = append(opts, discoverer.WithArtifactHubStrict(true), discoverer.WithOCIStrict(true))
+	}
+	if *artifactHubPreferredImage != "" {
+		opts = append(opts, discoverer.WithArtifactHubPreferredImage(*artifactHubPreferredImage))
+	}
+	if *ociRegistry != "" {
+		opts = append(opts, discoverer.WithOCIRegistry(*ociRegistry, *ociRepositoryPrefix))
+	}
+	if *ociUsername != "" {
+		opts = append(opts, discoverer.WithOCIRegistryAuth(*ociUsername, os.Getenv(ociPasswordEnv)))
+	}
+	dis, err := discoverer.New(*gadgetDiscoverer, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating gadget discoverer: %w", err)
+	}
+	gadgets, err := dis.ListGadgets()
+	if err != nil {
+		return nil, nil, err
+	}
+	images := make([]string, 0, len(gadgets))
+	categories := make(map[string][]string, len(gadgets))
+	for _, g := range gadgets {
+		images = append(images, g.Image)
+		categories[g.Image] = g.Categories
+	}
+	// Sort discovered images so that -max-gadget-tools selects the same gadgets on every start
+	slices.Sort(images)
+	return images, categories, nil
+}
+
+// serveMetrics serves the Prometheus metrics on addr under /metrics until ctx is done.
+func serveMetrics(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	log.Info("Serving metrics", "address", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error("failed to serve metrics", "error", err)
+	}
+}
+
+// reloadOnHangup lists the gadget images again and reloads the gadget tools on every SIGHUP, until ctx is done.
+// Reloads are serialized by the registry.
+func reloadOnHangup(ctx context.Context, registry *tools.GadgetToolRegistry) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+		log.Info("Received hangup signal, reloading gadget tools")
+		images, categories, err := listGadgetImages()
+		if err != nil {
+			log.Error("failed to list gadget images", "error", err)
+			continue
+		}
+		registry.SetGadgetCategories(categories)
+		if err = registry.Reload(ctx, images); err != nil {
+			log.Error("failed to reload tool registry", "error", err)
+		}
+	}
+}
+
 func logFatal(msg string, args ...any) {
 	log.Error(msg, args...)
 	os.Exit(1)
 }
 
+// setupLogging sets the default logger, which all package loggers use, to the given format and level.
+func setupLogging(format, level string) error {
+	opts := &slog.HandlerOptions{Level: slog.LevelInfo}
+	if level != "" {
+		l, err := parseLogLevel(level)
+		if err != nil {
+			return err
+		}
+		opts.Level = l
+	}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "text":
+		h = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid log format: %s", format)
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
 func parseLogLevel(level string) (slog.Level, error) {
 	switch strings.ToLower(level) {
 	case "debug":
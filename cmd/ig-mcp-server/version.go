@@ -0,0 +1,45 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "runtime/debug"
+
+const (
+	mcpGoModule           = "github.com/mark3labs/mcp-go"
+	inspektorGadgetModule = "github.com/inspektor-gadget/inspektor-gadget"
+)
+
+// versionAttrs returns the versions of the libraries the server is built with, as log attributes.
+func versionAttrs() []any {
+	attrs := []any{"version", version}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return attrs
+	}
+	attrs = append(attrs, "go", info.GoVersion)
+	for _, dep := range info.Deps {
+		v := dep.Version
+		if dep.Replace != nil {
+			v = dep.Replace.Path + "@" + dep.Replace.Version
+		}
+		switch dep.Path {
+		case mcpGoModule:
+			attrs = append(attrs, "mcp-go", v)
+		case inspektorGadgetModule:
+			attrs = append(attrs, "inspektor-gadget", v)
+		}
+	}
+	return attrs
+}
@@ -17,8 +17,6 @@ package deployer
 import (
 	"context"
 	"fmt"
-	"log/slog"
-	"net/http"
 	"os"
 	"time"
 
@@ -26,8 +24,12 @@ import (
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/release"
 
 	"github.com/inspektor-gadget/inspektor-gadget/cmd/kubectl-gadget/utils"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/httpclient"
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/logging"
 )
 
 const (
@@ -35,9 +37,7 @@ const (
 	LabelValueManagedBy = "ig-mcp-server"
 )
 
-const defaultHttpTimeout = 5 * time.Second
-
-var log = slog.Default().With("component", "inspektor-gadget-helm-deployer")
+var log = logging.Default().With("component", "inspektor-gadget-helm-deployer")
 
 var (
 	ErrChartURLNotSet        = fmt.Errorf("chart URL not set")
@@ -46,20 +46,25 @@ var (
 
 type helmDeployer struct {
 	registryClient *registry.Client
+	retries        int
 }
 
-func newHelmDeployer() (*helmDeployer, error) {
-	hc := http.Client{Timeout: defaultHttpTimeout}
-	opts := []registry.ClientOption{
-		registry.ClientOptHTTPClient(&hc),
+func newHelmDeployer(opts deployerOptions) (*helmDeployer, error) {
+	clientOpts := []registry.ClientOption{
+		registry.ClientOptHTTPClient(httpclient.New(httpclient.DefaultTimeout, opts.userAgent)),
 	}
-	rc, err := registry.NewClient(opts...)
+	rc, err := registry.NewClient(clientOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("create registry client: %w", err)
 	}
 
+	retries := defaultRetries
+	if opts.retries != nil {
+		retries = *opts.retries
+	}
 	return &helmDeployer{
 		registryClient: rc,
+		retries:        retries,
 	}, nil
 }
 
@@ -79,7 +84,15 @@ func (h *helmDeployer) Deploy(ctx context.Context, opts ...RunOption) error {
 		namespace = "gadget"
 	}
 
-	actionCfg, err := h.getActionConfig(namespace)
+	logf := debug
+	if cfg.progress != nil {
+		logf = func(format string, args ...any) {
+			debug(format, args...)
+			cfg.progress(fmt.Sprintf(format, args...))
+		}
+	}
+
+	actionCfg, err := h.getActionConfig(namespace, logf)
 	if err != nil {
 		return fmt.Errorf("get action configuration: %w", err)
 	}
@@ -87,16 +100,23 @@ func (h *helmDeployer) Deploy(ctx context.Context, opts ...RunOption) error {
 	install.ReleaseName = releaseName
 	install.Namespace = namespace
 	install.CreateNamespace = !cfg.skipNamespaceCreation
-	install.Wait = true
+	// When waiting for some gadget pods only, the release is considered installed right away and the pods are
+	// checked after
+	install.Wait = cfg.minReadyPods <= 0
 	install.Timeout = 30 * time.Second
 	install.Labels = map[string]string{
 		LabelKeyManagedBy: LabelValueManagedBy,
 	}
 
 	log.Debug("Deploying gadget", "chartUrl", chartUrl, "releaseName", releaseName, "namespace", namespace)
+	logf("Locating chart %s", chartUrl)
 
 	setting := cli.New()
-	chartPath, err := install.LocateChart(chartUrl, setting)
+	var chartPath string
+	err = retry(ctx, h.retries, "locate chart", func(int) error {
+		chartPath, err = install.LocateChart(chartUrl, setting)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("locate chart: %w", err)
 	}
@@ -105,11 +125,34 @@ func (h *helmDeployer) Deploy(ctx context.Context, opts ...RunOption) error {
 		return fmt.Errorf("load chart: %w", err)
 	}
 
-	release, err := install.RunWithContext(ctx, chart, map[string]interface{}{})
+	values := map[string]interface{}{}
+	if cfg.verifyGadgets != nil {
+		values["config"] = map[string]interface{}{
+			"verifyGadgets": *cfg.verifyGadgets,
+		}
+	}
+	logf("Installing release %s in namespace %s", releaseName, namespace)
+	var rel *release.Release
+	err = retry(ctx, h.retries, "install", func(attempt int) error {
+		// A failed attempt can leave the release behind, allow reusing its name
+		install.Replace = attempt > 0
+		rel, err = install.RunWithContext(ctx, chart, values)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("run install action: %w", err)
 	}
-	log.Debug("Successfully deployed Inspektor Gadget", "releaseName", release.Name, "namespace", release.Namespace)
+	if cfg.minReadyPods > 0 {
+		selector := cfg.podSelector
+		if selector == "" {
+			selector = DefaultPodSelector
+		}
+		logf("Waiting for %d gadget pods to be ready", cfg.minReadyPods)
+		if err := waitForReadyPods(ctx, namespace, selector, cfg.minReadyPods); err != nil {
+			return fmt.Errorf("wait for gadget pods: %w", err)
+		}
+	}
+	log.Debug("Successfully deployed Inspektor Gadget", "releaseName", rel.Name, "namespace", rel.Namespace)
 
 	return nil
 }
@@ -135,7 +178,7 @@ func (h *helmDeployer) Undeploy(ctx context.Context, opts ...RunOption) error {
 		return ErrNotDeployedByDeployer
 	}
 
-	actionCfg, err := h.getActionConfig(namespace)
+	actionCfg, err := h.getActionConfig(namespace, debug)
 	if err != nil {
 		return fmt.Errorf("get action configuration: %w", err)
 	}
@@ -165,7 +208,7 @@ func (h *helmDeployer) IsDeployed(ctx context.Context, opts ...RunOption) (bool,
 		namespace = "gadget"
 	}
 
-	actionCfg, err := h.getActionConfig(namespace)
+	actionCfg, err := h.getActionConfig(namespace, debug)
 	if err != nil {
 		return false, fmt.Errorf("get action configuration: %w", err)
 	}
@@ -185,10 +228,11 @@ func (h *helmDeployer) IsDeployed(ctx context.Context, opts ...RunOption) (bool,
 	return false, nil
 }
 
-func (h *helmDeployer) getActionConfig(namespace string) (*action.Configuration, error) {
+// getActionConfig returns the Helm action configuration for namespace, logging the actions with logf.
+func (h *helmDeployer) getActionConfig(namespace string, logf action.DebugLog) (*action.Configuration, error) {
 	actionConfig := action.Configuration{RegistryClient: h.registryClient}
 	// Namespace is used to define scope for the Helm installation and driver is used to store release information.
-	if err := actionConfig.Init(utils.KubernetesConfigFlags, namespace, os.Getenv("HELM_DRIVER"), debug); err != nil {
+	if err := actionConfig.Init(utils.KubernetesConfigFlags, namespace, os.Getenv("HELM_DRIVER"), logf); err != nil {
 		return nil, fmt.Errorf("initialize action configuration: %w", err)
 	}
 	return &actionConfig, nil
@@ -34,6 +34,14 @@ type Deployer interface {
 	IsDeployed(ctx context.Context, opts ...RunOption) (bool, error)
 }
 
+// Option configures a Deployer on creation.
+type Option func(*deployerOptions)
+
+type deployerOptions struct {
+	userAgent string
+	retries   *int
+}
+
 type RunOption func(*config)
 
 type config struct {
@@ -41,13 +49,25 @@ type config struct {
 	releaseName           string
 	namespace             string
 	skipNamespaceCreation bool
+	// verifyGadgets overrides whether the deployed Inspektor Gadget verifies image signatures, nil keeps the chart default
+	verifyGadgets *bool
+	// minReadyPods is the number of gadget pods to wait for instead of all of them, 0 waits for all
+	minReadyPods int
+	podSelector  string
+	// progress receives the progress messages of Deploy, e.g. the Helm install logs
+	progress func(msg string)
 }
 
 // NewDeployer creates a new Deployer based on the environment
-func NewDeployer(env string) (Deployer, error) {
+func NewDeployer(env string, opts ...Option) (Deployer, error) {
+	var o deployerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	switch env {
 	case KubernetesEnv:
-		return newHelmDeployer()
+		return newHelmDeployer(o)
 	}
 
 	return nil, fmt.Errorf("unsupported environment: %s", env)
@@ -59,6 +79,20 @@ func (c *config) applyOptions(opts ...RunOption) {
 	}
 }
 
+// WithUserAgent sets the user-agent used by the deployer for outbound HTTP requests.
+func WithUserAgent(userAgent string) Option {
+	return func(o *deployerOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithRetries sets how many times transient failures, e.g. registry timeouts, are retried when deploying.
+func WithRetries(retries int) Option {
+	return func(o *deployerOptions) {
+		o.retries = &retries
+	}
+}
+
 func WithChartURL(url string) RunOption {
 	return func(c *config) {
 		c.chartUrl = url
@@ -82,3 +116,28 @@ func WithSkipNamespaceCreation(skip bool) RunOption {
 		c.skipNamespaceCreation = skip
 	}
 }
+
+// WithVerifyGadgets sets whether the deployed Inspektor Gadget verifies the signature of gadget images before running
+// them, rejecting unsigned ones.
+func WithVerifyGadgets(verify bool) RunOption {
+	return func(c *config) {
+		c.verifyGadgets = &verify
+	}
+}
+
+// WithMinReadyPods makes Deploy wait until at least min gadget pods are ready instead of all of them, e.g. for
+// clusters with unschedulable nodes. selector is the label selector of the gadget pods, DefaultPodSelector if empty.
+func WithMinReadyPods(min int, selector string) RunOption {
+	return func(c *config) {
+		c.minReadyPods = min
+		c.podSelector = selector
+	}
+}
+
+// WithProgress sets a function receiving the progress messages of Deploy, including the logs of the Helm install,
+// e.g. to report them to a client while waiting for the deployment.
+func WithProgress(progress func(msg string)) RunOption {
+	return func(c *config) {
+		c.progress = progress
+	}
+}
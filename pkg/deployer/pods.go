@@ -0,0 +1,90 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/kubectl-gadget/utils"
+)
+
+const (
+	// DefaultPodSelector is the label selector of the pods deployed by the Inspektor Gadget chart
+	DefaultPodSelector = "k8s-app=gadget"
+
+	readyPodsTimeout  = 2 * time.Minute
+	readyPodsInterval = 2 * time.Second
+)
+
+// ReadyPods returns the number of ready pods matching selector in namespace, along with the total number of them.
+func ReadyPods(ctx context.Context, namespace, selector string) (ready int, total int, err error) {
+	config, err := utils.KubernetesConfigFlags.ToRESTConfig()
+	if err != nil {
+		return 0, 0, fmt.Errorf("creating RESTConfig: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return 0, 0, fmt.Errorf("creating Kubernetes client: %w", err)
+	}
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return 0, 0, fmt.Errorf("listing pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		if isPodReady(&pod) {
+			ready++
+		}
+	}
+	return ready, len(pods.Items), nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// waitForReadyPods waits until at least min pods matching selector in namespace are ready.
+func waitForReadyPods(ctx context.Context, namespace, selector string, min int) error {
+	ctx, cancel := context.WithTimeout(ctx, readyPodsTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(readyPodsInterval)
+	defer ticker.Stop()
+	for {
+		ready, total, err := ReadyPods(ctx, namespace, selector)
+		if err != nil {
+			return err
+		}
+		if ready >= min {
+			log.Debug("Enough Inspektor Gadget pods are ready", "ready", ready, "total", total, "min", min)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("only %d of the %d required gadget pods became ready (%d in total): %w", ready, min, total, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
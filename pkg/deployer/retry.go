@@ -0,0 +1,79 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const defaultRetries = 2
+
+// initialBackoff is the wait time before the first retry, it's a variable so tests don't have to wait
+var initialBackoff = time.Second
+
+// transientErrors are messages of registry responses worth retrying, they are only available as text in errors
+var transientErrors = []string{
+	"Internal Server Error",
+	"Bad Gateway",
+	"Service Unavailable",
+	"Gateway Timeout",
+	"Too Many Requests",
+}
+
+// isTransient reports whether err is likely to go away on retry e.g. a network timeout or a registry 5xx response.
+// Expired or cancelled contexts aren't, even though context.DeadlineExceeded is a net.Error timeout.
+func isTransient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	for _, msg := range transientErrors {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// retry calls fn until it succeeds, fails with an error that isn't transient or the retries are exhausted, doubling
+// the wait time between attempts.
+func retry(ctx context.Context, retries int, op string, fn func(attempt int) error) error {
+	backoff := initialBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || attempt >= retries || !isTransient(err) {
+			return err
+		}
+		log.Debug("Retrying after transient error", "operation", op, "attempt", attempt+1, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
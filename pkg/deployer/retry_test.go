@@ -0,0 +1,128 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{name: "timeout", err: &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, transient: true},
+		{name: "connection reset", err: fmt.Errorf("pulling chart: %w", syscall.ECONNRESET), transient: true},
+		{name: "connection refused", err: fmt.Errorf("pulling chart: %w", syscall.ECONNREFUSED), transient: true},
+		{name: "unexpected EOF", err: fmt.Errorf("reading chart: %w", io.ErrUnexpectedEOF), transient: true},
+		{name: "server error", err: errors.New("failed to fetch: 503 Service Unavailable"), transient: true},
+		{name: "rate limited", err: errors.New("failed to fetch: 429 Too Many Requests"), transient: true},
+		{name: "not found", err: errors.New("failed to fetch: 404 Not Found"), transient: false},
+		{name: "unauthorized", err: errors.New("failed to authorize: 401 Unauthorized"), transient: false},
+		{name: "non-timeout network error", err: &net.OpError{Op: "dial", Err: errors.New("no such host")}, transient: false},
+		{name: "EOF", err: io.EOF, transient: false},
+		{name: "context deadline", err: fmt.Errorf("waiting for pods: %w", context.DeadlineExceeded), transient: false},
+		{name: "context cancelled", err: fmt.Errorf("pulling chart: %w", context.Canceled), transient: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(tt.err); got != tt.transient {
+				t.Errorf("isTransient(%v): expected %v, got %v", tt.err, tt.transient, got)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	transient := errors.New("503 Service Unavailable")
+	permanent := errors.New("404 Not Found")
+	waitDeadline := fmt.Errorf("waiting for gadget pods: %w", context.DeadlineExceeded)
+	tests := []struct {
+		name     string
+		retries  int
+		errs     []error
+		attempts int
+		err      error
+	}{
+		{name: "success", retries: 2, errs: []error{nil}, attempts: 1},
+		{name: "success after retry", retries: 2, errs: []error{transient, nil}, attempts: 2},
+		{name: "retries exhausted", retries: 2, errs: []error{transient, transient, transient, nil}, attempts: 3, err: transient},
+		{name: "no retries", retries: 0, errs: []error{transient, nil}, attempts: 1, err: transient},
+		{name: "not transient", retries: 2, errs: []error{permanent, nil}, attempts: 1, err: permanent},
+		{name: "wait deadline", retries: 2, errs: []error{waitDeadline, nil}, attempts: 1, err: waitDeadline},
+	}
+
+	orig := initialBackoff
+	defer func() { initialBackoff = orig }()
+	initialBackoff = time.Millisecond
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := retry(context.Background(), tt.retries, "test", func(attempt int) error {
+				if attempt != attempts {
+					t.Errorf("expected attempt %d, got %d", attempts, attempt)
+				}
+				attempts++
+				return tt.errs[attempt]
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected error %v, got %v", tt.err, err)
+			}
+			if attempts != tt.attempts {
+				t.Errorf("expected %d attempts, got %d", tt.attempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryCancelled(t *testing.T) {
+	orig := initialBackoff
+	defer func() { initialBackoff = orig }()
+	initialBackoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	transient := errors.New("503 Service Unavailable")
+	attempts := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- retry(ctx, 2, "test", func(int) error {
+			attempts++
+			cancel()
+			return transient
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, transient) {
+			t.Errorf("expected the last error, got %v", err)
+		}
+		if attempts != 1 {
+			t.Errorf("expected no retry once cancelled, got %d attempts", attempts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the cancellation to stop the backoff")
+	}
+}
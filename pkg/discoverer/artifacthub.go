@@ -17,7 +17,13 @@ package discoverer
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/httpclient"
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/metrics"
 )
 
 const SourceArtifactHub = "artifacthub"
@@ -37,52 +43,104 @@ type ArtifacthubPackage struct {
 }
 
 type ArtifacthubPackageDetails struct {
-	ContainersImages []struct {
-		Name  string `json:"name"`
-		Image string `json:"image"`
-	} `json:"containers_images"`
+	Keywords         []string                    `json:"keywords"`
+	ContainersImages []ArtifacthubContainerImage `json:"containers_images"`
+}
+
+type ArtifacthubContainerImage struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
 }
 
 type artifactHubDiscoverer struct {
-	officialOnly bool
+	officialOnly   bool
+	preferredImage string
+	strict         bool
+	versionPins    map[string]string
+	client         *http.Client
 }
 
 func NewArtifactHubDiscoverer(cfg Config) Discoverer {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = httpclient.New(0, cfg.UserAgent)
+	}
 	return &artifactHubDiscoverer{
-		officialOnly: cfg.Artifacthub.OfficialOnly,
+		officialOnly:   cfg.Artifacthub.OfficialOnly,
+		preferredImage: cfg.Artifacthub.PreferredImage,
+		strict:         cfg.Artifacthub.Strict,
+		versionPins:    cfg.VersionPins,
+		client:         client,
 	}
 }
 
 func (d *artifactHubDiscoverer) ListImages() ([]string, error) {
+	gadgets, err := d.ListGadgets()
+	if err != nil {
+		return nil, err
+	}
+	images := make([]string, 0, len(gadgets))
+	for _, g := range gadgets {
+		images = append(images, g.Image)
+	}
+	return images, nil
+}
+
+func (d *artifactHubDiscoverer) ListGadgets() ([]GadgetRef, error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveDuration(metrics.DiscoveryDuration.WithLabelValues("artifacthub"), start)
+		log.Debug("listed images from Artifact Hub", "duration", time.Since(start))
+	}()
+
 	packages, err := d.listPackages()
 	if err != nil {
 		return nil, fmt.Errorf("listing packages from Artifact Hub: %w", err)
 	}
 
-	var images []string
+	var gadgets []GadgetRef
+	var failed int
+	var lastErr error
 	for _, pkg := range packages.Packages {
 		if d.officialOnly && !pkg.Official {
 			log.Debug("skipping non-official package", "package", pkg.NormalizedName)
 			continue
 		}
-		image, err := d.getPackageImage(pkg.NormalizedName)
+		details, err := d.getPackageDetails(pkg.NormalizedName)
 		if err != nil {
-			log.Warn("failed to get image for package", "package", pkg.NormalizedName, "error", err)
+			if d.strict {
+				return nil, fmt.Errorf("getting image for package %s: %w", pkg.NormalizedName, err)
+			}
+			log.Warn("failed to get image for package, skipping it", "package", pkg.NormalizedName, "error", err)
+			failed++
+			lastErr = err
 			continue
 		}
-		images = append(images, image)
+		image := selectImage(details.ContainersImages, d.preferredImage)
+		if version, ok := d.versionPins[pkg.NormalizedName]; ok {
+			log.Debug("pinning gadget version", "package", pkg.NormalizedName, "version", version)
+			image = pinImage(image, version)
+		}
+		gadgets = append(gadgets, GadgetRef{Image: image, Categories: details.Keywords})
 	}
-	return images, nil
+	// Skipping makes sense for a few broken packages, not when Artifact Hub can't be used at all
+	if len(gadgets) == 0 && failed > 0 {
+		return nil, fmt.Errorf("getting images of all %d packages failed, last error: %w", failed, lastErr)
+	}
+	if failed > 0 {
+		log.Warn("some Artifact Hub packages were skipped", "skipped", failed, "discovered", len(gadgets))
+	}
+	return gadgets, nil
 }
 
 func (d *artifactHubDiscoverer) listPackages() (*ArtifacthubPackages, error) {
 	// Gadget packages are listed under kind 22 in Artifact Hub
 	url := "https://artifacthub.io/api/v1/packages/search?kind=22&limit=60"
-	resp, err := http.Get(url)
+	resp, err := d.client.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("fetching packages from Artifact Hub: %w", err)
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status code from Artifact Hub: %d", resp.StatusCode)
@@ -96,24 +154,44 @@ func (d *artifactHubDiscoverer) listPackages() (*ArtifacthubPackages, error) {
 	return &packages, nil
 }
 
-func (d *artifactHubDiscoverer) getPackageImage(name string) (string, error) {
+// getPackageDetails returns the details of a package, which has at least one container image.
+func (d *artifactHubDiscoverer) getPackageDetails(name string) (*ArtifacthubPackageDetails, error) {
 	url := fmt.Sprintf("https://artifacthub.io/api/v1/packages/inspektor-gadget/gadgets/%s", name)
-	resp, err := http.Get(url)
+	resp, err := d.client.Get(url)
 	if err != nil {
-		return "", fmt.Errorf("fetching package details from Artifact Hub: %w", err)
+		return nil, fmt.Errorf("fetching package details from Artifact Hub: %w", err)
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code from Artifact Hub: %d", resp.StatusCode)
+		return nil, fmt.Errorf("unexpected status code from Artifact Hub: %d", resp.StatusCode)
 	}
 
 	var details ArtifacthubPackageDetails
 	if err = json.NewDecoder(resp.Body).Decode(&details); err != nil {
-		return "", fmt.Errorf("decoding package details from Artifact Hub: %w", err)
+		return nil, fmt.Errorf("decoding package details from Artifact Hub: %w", err)
 	}
 	if len(details.ContainersImages) == 0 {
-		return "", fmt.Errorf("no container images found for package %s", name)
+		return nil, fmt.Errorf("no container images found for package %s", name)
 	}
-	return details.ContainersImages[0].Image, nil
+	return &details, nil
+}
+
+// selectImage returns the first image whose name or reference contains preferred, falling back to the first one.
+func selectImage(images []ArtifacthubContainerImage, preferred string) string {
+	if preferred != "" {
+		for _, img := range images {
+			if strings.Contains(img.Name, preferred) || strings.Contains(img.Image, preferred) {
+				return img.Image
+			}
+		}
+		log.Debug("no image matches the preferred one, using the first", "preferred", preferred, "image", images[0].Image)
+	}
+	return images[0].Image
+}
+
+// drainAndClose reads the remaining body so the underlying connection can be reused
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, body)
+	body.Close()
 }
@@ -17,25 +17,57 @@ package discoverer
 import (
 	"errors"
 	"fmt"
-	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/logging"
 )
 
 var ErrUnknownSource = errors.New("unknown source")
 
-var log = slog.Default().With("component", "discoverer")
+var log = logging.Default().With("component", "discoverer")
 
 type Option func(*Config)
 
 type Config struct {
+	UserAgent string
+	// HTTPClient is shared by all requests of a discoverer, a new one is created if not set
+	HTTPClient *http.Client
+	// VersionPins maps gadget names to the version (tag) to use for them instead of the discovered one
+	VersionPins map[string]string
 	Artifacthub struct {
 		OfficialOnly bool
+		// PreferredImage selects the first container image whose name or reference contains it, for packages
+		// listing multiple images. The first image is used if none matches.
+		PreferredImage string
+		// Strict fails the discovery if the image of any package can't be resolved instead of skipping the package
+		Strict bool
+	}
+	OCI struct {
+		// Registry is the host of the registry listed by the oci discoverer e.g. registry.example.com:5000
+		Registry string
+		// Prefix limits the discovered repositories to the ones under it e.g. team/gadgets
+		Prefix   string
+		Username string
+		Password string
+		// Strict fails the discovery if any repository can't be read instead of skipping it
+		Strict bool
 	}
 }
 
+// GadgetRef is a gadget found by a discoverer.
+type GadgetRef struct {
+	Image string
+	// Categories are the keywords the source gives to the gadget e.g. "trace" or "dns", if any
+	Categories []string
+}
+
 // Discoverer is used to discover available gadgets from various sources.
 type Discoverer interface {
 	// ListImages returns a list of available gadget images.
 	ListImages() ([]string, error)
+	// ListGadgets returns the available gadgets, with the metadata known to the source.
+	ListGadgets() ([]GadgetRef, error)
 }
 
 func New(source string, opts ...Option) (Discoverer, error) {
@@ -47,6 +79,8 @@ func New(source string, opts ...Option) (Discoverer, error) {
 	switch source {
 	case SourceArtifactHub:
 		return NewArtifactHubDiscoverer(cfg), nil
+	case SourceOCI:
+		return NewOCIDiscoverer(cfg)
 	}
 	return nil, fmt.Errorf("%w: %s", ErrUnknownSource, source)
 }
@@ -56,3 +90,73 @@ func WithArtifactHubOfficialOnly(officialOnly bool) Option {
 		cfg.Artifacthub.OfficialOnly = officialOnly
 	}
 }
+
+// WithArtifactHubPreferredImage sets a substring used to pick an image for packages listing multiple ones.
+func WithArtifactHubPreferredImage(preferred string) Option {
+	return func(cfg *Config) {
+		cfg.Artifacthub.PreferredImage = preferred
+	}
+}
+
+// WithArtifactHubStrict makes the discovery fail if the image of any package can't be resolved. By default, such
+// packages are skipped so that one broken package doesn't prevent discovering all the others.
+func WithArtifactHubStrict(strict bool) Option {
+	return func(cfg *Config) {
+		cfg.Artifacthub.Strict = strict
+	}
+}
+
+// WithOCIRegistry sets the registry listed by the oci discoverer, and the repository prefix gadgets are under, if any.
+func WithOCIRegistry(host, prefix string) Option {
+	return func(cfg *Config) {
+		cfg.OCI.Registry = host
+		cfg.OCI.Prefix = prefix
+	}
+}
+
+// WithOCIRegistryAuth sets the credentials of the registry listed by the oci discoverer. If not set, the ones of the
+// Docker configuration are used, if any.
+func WithOCIRegistryAuth(username, password string) Option {
+	return func(cfg *Config) {
+		cfg.OCI.Username = username
+		cfg.OCI.Password = password
+	}
+}
+
+// WithOCIStrict makes the discovery fail if any repository of the registry can't be read. By default, such repositories
+// are skipped so that one broken repository doesn't prevent discovering all the others. Repositories that aren't
+// gadgets are always skipped.
+func WithOCIStrict(strict bool) Option {
+	return func(cfg *Config) {
+		cfg.OCI.Strict = strict
+	}
+}
+
+// WithVersionPins pins the given gadgets, by name, to a specific version. Other gadgets use the discovered version.
+func WithVersionPins(pins map[string]string) Option {
+	return func(cfg *Config) {
+		cfg.VersionPins = pins
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(cfg *Config) {
+		cfg.UserAgent = userAgent
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the discoverer. It takes precedence over WithUserAgent.
+func WithHTTPClient(client *http.Client) Option {
+	return func(cfg *Config) {
+		cfg.HTTPClient = client
+	}
+}
+
+// pinImage replaces the tag (and digest) of image with version.
+func pinImage(image, version string) string {
+	repo, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	return repo + ":" + version
+}
@@ -0,0 +1,261 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoverer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/httpclient"
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/metrics"
+)
+
+const SourceOCI = "oci"
+
+const (
+	// gadgetArtifactType and gadgetConfigMediaType identify the manifests of gadget images, see
+	// github.com/inspektor-gadget/inspektor-gadget/pkg/oci
+	gadgetArtifactType    = "application/vnd.gadget.v1+binary"
+	gadgetConfigMediaType = "application/vnd.gadget.config.v1+yaml"
+
+	ociDiscoverTimeout = 2 * time.Minute
+	maxManifestBytes   = 4 * 1024 * 1024
+)
+
+var (
+	errNoGadgetTag = errors.New("no tag to use")
+	errNotGadget   = errors.New("not a gadget image")
+)
+
+type ociDiscoverer struct {
+	registry    *remote.Registry
+	host        string
+	prefix      string
+	versionPins map[string]string
+	strict      bool
+}
+
+// NewOCIDiscoverer creates a discoverer listing the gadget images of an OCI registry with its catalog, see
+// WithOCIRegistry. Credentials are the ones set with WithOCIRegistryAuth, or the ones of the Docker configuration.
+func NewOCIDiscoverer(cfg Config) (Discoverer, error) {
+	if cfg.OCI.Registry == "" {
+		return nil, errors.New("oci discoverer: registry not set")
+	}
+	reg, err := remote.NewRegistry(cfg.OCI.Registry)
+	if err != nil {
+		return nil, fmt.Errorf("oci discoverer: %w", err)
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = httpclient.New(0, cfg.UserAgent)
+	}
+	client := &auth.Client{
+		Client: httpClient,
+		Cache:  auth.NewCache(),
+	}
+	switch {
+	case cfg.OCI.Username != "":
+		client.Credential = auth.StaticCredential(reg.Reference.Registry, auth.Credential{
+			Username: cfg.OCI.Username,
+			Password: cfg.OCI.Password,
+		})
+	default:
+		store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+		if err != nil {
+			log.Debug("Docker credentials not available, accessing the registry anonymously", "error", err)
+		} else {
+			client.Credential = credentials.Credential(store)
+		}
+	}
+	reg.Client = client
+
+	return &ociDiscoverer{
+		registry:    reg,
+		host:        cfg.OCI.Registry,
+		prefix:      strings.Trim(cfg.OCI.Prefix, "/"),
+		versionPins: cfg.VersionPins,
+		strict:      cfg.OCI.Strict,
+	}, nil
+}
+
+func (d *ociDiscoverer) ListImages() ([]string, error) {
+	gadgets, err := d.ListGadgets()
+	if err != nil {
+		return nil, err
+	}
+	images := make([]string, 0, len(gadgets))
+	for _, g := range gadgets {
+		images = append(images, g.Image)
+	}
+	return images, nil
+}
+
+func (d *ociDiscoverer) ListGadgets() ([]GadgetRef, error) {
+	start := time.Now()
+	defer func() {
+		metrics.ObserveDuration(metrics.DiscoveryDuration.WithLabelValues("oci"), start)
+		log.Debug("listed images from OCI registry", "registry", d.host, "duration", time.Since(start))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ociDiscoverTimeout)
+	defer cancel()
+
+	var repos []string
+	err := d.registry.Repositories(ctx, "", func(page []string) error {
+		for _, repo := range page {
+			if d.prefix == "" || strings.HasPrefix(repo, d.prefix+"/") {
+				repos = append(repos, repo)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing repositories of %s: %w", d.host, err)
+	}
+
+	var gadgets []GadgetRef
+	var failed int
+	var lastErr error
+	for _, repo := range repos {
+		image, err := d.gadgetImage(ctx, repo)
+		switch {
+		case errors.Is(err, errNotGadget) || errors.Is(err, errNoGadgetTag):
+			// Registries can hold other images than gadgets
+			log.Debug("skipping repository", "repository", repo, "error", err)
+			continue
+		case err != nil && d.strict:
+			return nil, fmt.Errorf("reading repository %s: %w", repo, err)
+		case err != nil:
+			log.Warn("failed to read repository, skipping it", "repository", repo, "error", err)
+			failed++
+			lastErr = err
+			continue
+		}
+		gadgets = append(gadgets, GadgetRef{Image: image})
+	}
+	if len(gadgets) == 0 && failed > 0 {
+		return nil, fmt.Errorf("reading all %d repositories of %s failed, last error: %w", failed, d.host, lastErr)
+	}
+	if failed > 0 {
+		log.Warn("some repositories were skipped", "registry", d.host, "skipped", failed, "discovered", len(gadgets))
+	}
+	return gadgets, nil
+}
+
+// gadgetImage returns the image to use for repo, at its pinned version, else the tag picked by selectTag. An error is
+// returned if the image isn't a gadget.
+func (d *ociDiscoverer) gadgetImage(ctx context.Context, name string) (string, error) {
+	repo, err := d.registry.Repository(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	tag, ok := d.versionPins[path.Base(name)]
+	if ok {
+		log.Debug("pinning gadget version", "repository", name, "version", tag)
+	} else {
+		var tags []string
+		err = repo.Tags(ctx, "", func(page []string) error {
+			tags = append(tags, page...)
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("listing tags: %w", err)
+		}
+		if tag = selectTag(tags); tag == "" {
+			return "", errNoGadgetTag
+		}
+	}
+
+	desc, rc, err := repo.FetchReference(ctx, tag)
+	if err != nil {
+		return "", fmt.Errorf("fetching %s: %w", tag, err)
+	}
+	data, err := readManifest(rc)
+	if err != nil {
+		return "", err
+	}
+	if desc.MediaType == ocispec.MediaTypeImageIndex {
+		// Gadget images are indexes of a manifest per architecture, check the first one
+		var index ocispec.Index
+		if err := json.Unmarshal(data, &index); err != nil {
+			return "", fmt.Errorf("decoding index: %w", err)
+		}
+		if len(index.Manifests) == 0 {
+			return "", errors.New("empty index")
+		}
+		rc, err := repo.Fetch(ctx, index.Manifests[0])
+		if err != nil {
+			return "", fmt.Errorf("fetching manifest: %w", err)
+		}
+		if data, err = readManifest(rc); err != nil {
+			return "", err
+		}
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("decoding manifest: %w", err)
+	}
+	if manifest.ArtifactType != gadgetArtifactType && manifest.Config.MediaType != gadgetConfigMediaType {
+		return "", errNotGadget
+	}
+	return d.host + "/" + name + ":" + tag, nil
+}
+
+// selectTag returns the tag to use among tags: latest, else the highest semantic version, else the last one. Tags are
+// listed in lexical order, so the last one isn't the highest version e.g. v0.9.0 comes after v0.41.0.
+func selectTag(tags []string) string {
+	if slices.Contains(tags, "latest") {
+		return "latest"
+	}
+	var tag string
+	var highest *semver.Version
+	for _, t := range tags {
+		v, err := semver.NewVersion(t)
+		if err != nil {
+			continue
+		}
+		if highest == nil || v.GreaterThan(highest) {
+			tag, highest = t, v
+		}
+	}
+	if highest == nil && len(tags) > 0 {
+		tag = tags[len(tags)-1]
+	}
+	return tag
+}
+
+func readManifest(rc io.ReadCloser) ([]byte, error) {
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxManifestBytes))
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package discoverer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// testRegistry is a minimal OCI registry serving the catalog, the tags and the manifests of its repositories.
+type testRegistry struct {
+	// tags maps repositories to their tags
+	tags map[string][]string
+	// configMediaTypes maps repositories to the config media type of their manifests, gadgets by default
+	configMediaTypes map[string]string
+	// broken repositories fail to serve their manifests
+	broken []string
+}
+
+func (reg *testRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := strings.TrimPrefix(r.URL.Path, "/v2/")
+	switch {
+	case p == "_catalog":
+		var repos []string
+		for repo := range reg.tags {
+			repos = append(repos, repo)
+		}
+		slices.Sort(repos)
+		writeJSON(w, "application/json", map[string]any{"repositories": repos})
+	case strings.HasSuffix(p, "/tags/list"):
+		repo := strings.TrimSuffix(p, "/tags/list")
+		writeJSON(w, "application/json", map[string]any{"name": repo, "tags": reg.tags[repo]})
+	case strings.Contains(p, "/manifests/"):
+		repo, _, _ := strings.Cut(p, "/manifests/")
+		if slices.Contains(reg.broken, repo) {
+			http.Error(w, "broken", http.StatusInternalServerError)
+			return
+		}
+		configMediaType := gadgetConfigMediaType
+		if mt, ok := reg.configMediaTypes[repo]; ok {
+			configMediaType = mt
+		}
+		writeJSON(w, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Config: ocispec.Descriptor{
+				MediaType: configMediaType,
+				Digest:    digest.FromString(repo),
+				Size:      int64(len(repo)),
+			},
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, mediaType string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+	w.Header().Set("Docker-Content-Digest", digest.FromBytes(data).String())
+	w.Write(data)
+}
+
+func newTestOCIDiscoverer(t *testing.T, reg *testRegistry, prefix string, opts ...Option) (Discoverer, string) {
+	t.Helper()
+	// Don't use the credentials of the Docker configuration of the host
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	srv := httptest.NewTLSServer(reg)
+	t.Cleanup(srv.Close)
+
+	host := strings.TrimPrefix(srv.URL, "https://")
+	opts = append(opts, WithOCIRegistry(host, prefix), func(cfg *Config) {
+		cfg.HTTPClient = srv.Client()
+	})
+	d, err := New(SourceOCI, opts...)
+	if err != nil {
+		t.Fatalf("creating discoverer: %v", err)
+	}
+	return d, host
+}
+
+func TestOCIListImages(t *testing.T) {
+	reg := &testRegistry{
+		tags: map[string][]string{
+			"gadgets/trace_dns":  {"v0.41.0", "v0.9.0"},
+			"gadgets/trace_open": {"latest", "v0.41.0"},
+			"gadgets/busybox":    {"latest"},
+			"gadgets/untagged":   {},
+			"gadgets/broken":     {"latest"},
+			"other/trace_exec":   {"latest"},
+		},
+		configMediaTypes: map[string]string{"gadgets/busybox": ocispec.MediaTypeImageConfig},
+		broken:           []string{"gadgets/broken"},
+	}
+	d, host := newTestOCIDiscoverer(t, reg, "gadgets")
+
+	images, err := d.ListImages()
+	if err != nil {
+		t.Fatalf("listing images: %v", err)
+	}
+	expected := []string{host + "/gadgets/trace_dns:v0.41.0", host + "/gadgets/trace_open:latest"}
+	if !slices.Equal(images, expected) {
+		t.Errorf("expected images %v, got %v", expected, images)
+	}
+}
+
+func TestOCIListImagesAllFailed(t *testing.T) {
+	reg := &testRegistry{
+		tags: map[string][]string{
+			"trace_dns":  {"latest"},
+			"trace_open": {"latest"},
+		},
+		broken: []string{"trace_dns", "trace_open"},
+	}
+	d, _ := newTestOCIDiscoverer(t, reg, "")
+
+	if images, err := d.ListImages(); err == nil {
+		t.Errorf("expected an error when no repository can be read, got %v", images)
+	}
+}
+
+func TestOCIListImagesStrict(t *testing.T) {
+	reg := &testRegistry{
+		tags: map[string][]string{
+			"trace_dns": {"latest"},
+			"broken":    {"latest"},
+		},
+		broken: []string{"broken"},
+	}
+	d, _ := newTestOCIDiscoverer(t, reg, "", WithOCIStrict(true))
+	if images, err := d.ListImages(); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected an error for the broken repository, got %v, %v", images, err)
+	}
+
+	// Repositories that aren't gadgets aren't errors
+	reg = &testRegistry{
+		tags:             map[string][]string{"trace_dns": {"latest"}, "busybox": {"latest"}},
+		configMediaTypes: map[string]string{"busybox": ocispec.MediaTypeImageConfig},
+	}
+	d, _ = newTestOCIDiscoverer(t, reg, "", WithOCIStrict(true))
+	images, err := d.ListImages()
+	if err != nil {
+		t.Fatalf("listing images: %v", err)
+	}
+	if len(images) != 1 {
+		t.Errorf("expected a single gadget, got %v", images)
+	}
+}
+
+func TestSelectTag(t *testing.T) {
+	tests := []struct {
+		tags     []string
+		expected string
+	}{
+		{tags: []string{"v0.40.0", "v0.41.0", "latest"}, expected: "latest"},
+		{tags: []string{"v0.41.0", "v0.9.0"}, expected: "v0.41.0"},
+		{tags: []string{"0.10.0", "0.2.0", "main"}, expected: "0.10.0"},
+		{tags: []string{"v1.0.0-rc.1", "v0.41.0", "v1.0.0"}, expected: "v1.0.0"},
+		{tags: []string{"dev", "main"}, expected: "main"},
+		{tags: nil, expected: ""},
+	}
+	for _, tt := range tests {
+		if got := selectTag(tt.tags); got != tt.expected {
+			t.Errorf("selectTag(%v): expected %q, got %q", tt.tags, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimestampField is the event field used to order events, e.g. for cursors.
+const TimestampField = "timestamp"
+
+// cursorPrefix versions the cursor format, cursors are opaque to callers. A cursor holds the timestamp of the newest
+// event returned and how many events with that timestamp were returned, so that events sharing it aren't lost.
+const cursorPrefix = "t1:"
+
+// EventLines returns the non-empty lines of a newline-delimited JSON output.
+func EventLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// EventTime returns the value of the timestamp field of an event. Numbers are treated as nanoseconds since the
+// epoch and strings as RFC 3339 timestamps.
+func EventTime(line string) (time.Time, bool) {
+	var event map[string]any
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		return time.Time{}, false
+	}
+	switch ts := event[TimestampField].(type) {
+	case float64:
+		return time.Unix(0, int64(ts)), true
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+// cursorPos is the position of a cursor: n events with timestamp t, the newest ones, were returned.
+type cursorPos struct {
+	t time.Time
+	n int
+}
+
+func encodeCursor(pos cursorPos) string {
+	return cursorPrefix + strconv.FormatInt(pos.t.UnixNano(), 10) + ":" + strconv.Itoa(pos.n)
+}
+
+func decodeCursor(cursor string) (cursorPos, error) {
+	if cursor == "" {
+		return cursorPos{}, nil
+	}
+	rest, ok := strings.CutPrefix(cursor, cursorPrefix)
+	if !ok {
+		return cursorPos{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	ns, count, ok := strings.Cut(rest, ":")
+	if !ok {
+		return cursorPos{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	n, err := strconv.ParseInt(ns, 10, 64)
+	if err != nil {
+		return cursorPos{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	c, err := strconv.Atoi(count)
+	if err != nil || c < 0 {
+		return cursorPos{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return cursorPos{t: time.Unix(0, n), n: c}, nil
+}
+
+// advance moves the position past the given returned event lines.
+func (pos cursorPos) advance(lines []string) cursorPos {
+	for _, line := range lines {
+		t, ok := EventTime(line)
+		switch {
+		case !ok:
+		case t.After(pos.t):
+			pos = cursorPos{t: t, n: 1}
+		case t.Equal(pos.t):
+			pos.n++
+		}
+	}
+	return pos
+}
+
+// NextCursor returns the cursor to pass to Results to get the events after lines, the events returned by the call
+// that was given cursor, or the leading part of them e.g. when the output had to be truncated.
+func NextCursor(cursor string, lines []string) (string, error) {
+	pos, err := decodeCursor(cursor)
+	if err != nil {
+		return "", err
+	}
+	pos = pos.advance(lines)
+	if pos.t.IsZero() {
+		return cursor, nil
+	}
+	return encodeCursor(pos), nil
+}
+
+// ApplyCursor drops the events of result that were returned before cursor and sets the cursor to pass on the next
+// call. Events without a timestamp can't be ordered, they are always kept and so returned again on every call.
+func ApplyCursor(result *RunResult, cursor string) error {
+	pos, err := decodeCursor(cursor)
+	if err != nil {
+		return err
+	}
+	var kept []string
+	skipped := 0
+	for _, line := range EventLines(result.Output) {
+		if t, ok := EventTime(line); ok {
+			if t.Before(pos.t) {
+				continue
+			}
+			// Events with the timestamp of the cursor are in the order they were returned, skip the ones that were
+			if t.Equal(pos.t) && skipped < pos.n {
+				skipped++
+				continue
+			}
+		}
+		kept = append(kept, line)
+	}
+	var out strings.Builder
+	for _, line := range kept {
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	result.Output = out.String()
+	result.Events = len(kept)
+	result.Cursor = cursor
+	if next := pos.advance(kept); !next.t.IsZero() {
+		result.Cursor = encodeCursor(next)
+	}
+	return nil
+}
@@ -16,9 +16,16 @@ package gadgetmanager
 
 import (
 	"context"
-	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/inspektor-gadget/inspektor-gadget/cmd/kubectl-gadget/utils"
@@ -27,39 +34,276 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/environment"
 	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
 	igruntime "github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
 	grpcruntime "github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/logging"
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/metrics"
 )
 
+var log = logging.Default().With("component", "gadgetmanager")
+
+// Runtimes supported by NewGadgetManager
+const (
+	RuntimeGrpcK8s   = "grpc-k8s"
+	RuntimeGrpcLinux = "grpc-linux"
+)
+
+// nodeFieldName is the field Kubernetes gadgets use for the node an event comes from
+const nodeFieldName = "k8s.node"
+
 // GadgetManager is an interface for managing gadgets.
 type GadgetManager interface {
-	// Run starts a gadget with the given image and parameters, returning the output as a string.
-	Run(image string, params map[string]string, timeout time.Duration) (string, error)
-	// RunDetached starts a gadget with the given image and parameters in the background, returning its ID.
-	RunDetached(image string, params map[string]string) (string, error)
-	// Results returns the stored result buffer from a gadget
-	Results(id string) (string, error)
+	// Run starts a gadget with the given image and parameters, returning the collected output. If nodes is set, the
+	// gadget only runs on those nodes.
+	Run(image string, params map[string]string, timeout time.Duration, nodes []string, opts ...RunOption) (*RunResult, error)
+	// RunDetached starts a gadget with the given image and parameters in the background, returning its ID. If nodes
+	// is set, the gadget only runs on those nodes. See WithSubscriber to receive its events as they are emitted.
+	RunDetached(image string, params map[string]string, nodes []string, opts ...RunOption) (string, error)
+	// Results returns the stored result buffer from a gadget. If cursor is set, only the events newer than the
+	// ones returned by the call that gave that cursor are returned.
+	Results(id string, cursor string) (*RunResult, error)
 	// Stop stops a gadget
 	Stop(id string) error
+	// SetInstanceLabels attaches labels, e.g. for correlation in downstream systems, to a gadget instance started
+	// by RunDetached. They are reported by ListInstances, InstanceStatus and Results.
+	SetInstanceLabels(id string, labels map[string]string) error
+	// TailCursor returns the cursor stored with SetTailCursor for a gadget instance, empty if none.
+	TailCursor(id string) string
+	// SetTailCursor stores the cursor of the results last followed for a gadget instance started by RunDetached, it
+	// is forgotten along with the instance. It returns ErrInstanceNotFound for other instances.
+	SetTailCursor(id, cursor string) error
+	// ForegroundRuns returns the Run calls in progress.
+	ForegroundRuns() []ForegroundRun
+	// CancelRun cancels the Run call in progress with the given ID, the call returns the output collected so far.
+	CancelRun(id string) error
+	// ListInstances returns all gadget instances running on the target system, including the ones
+	// not started by this manager.
+	ListInstances(ctx context.Context) ([]GadgetInstance, error)
+	// InstanceStatus returns the gadget instance with the given ID, see InstanceStateStopped for instances that aren't
+	// running anymore.
+	InstanceStatus(ctx context.Context, id string) (*GadgetInstance, error)
 	// GetInfo retrieves information about a gadget image via runtime.
 	GetInfo(ctx context.Context, image string) (*api.GadgetInfo, error)
+	// ResolveImage pulls image, if needed, and returns the digest and metadata hash it resolves to.
+	ResolveImage(ctx context.Context, image string) (*ImageInfo, error)
 	// Close closes the gadget manager and releases any resources.
 	Close() error
 }
 
+// RunResult holds the output of a gadget run along with some metadata about it.
+type RunResult struct {
+	// Output contains the events as newline-delimited JSON
+	Output string
+	// Events is the number of events in Output
+	Events int
+	// DataSources are the names of the data sources that emitted events
+	DataSources []string
+	// Nodes are the nodes that emitted events, only known for events with a k8s.node field
+	Nodes []string
+	// Cancelled is true if the run was cancelled with CancelRun before its timeout
+	Cancelled bool
+	// TimedOut is true if the runtime reported a deadline exceeded after some events were collected, Output may be
+	// incomplete
+	TimedOut bool
+	// Messages are the warnings and errors logged during the run, separately from the events, e.g. about dropped
+	// events
+	Messages []string
+	// Labels are the labels of the gadget instance set with SetInstanceLabels, only set by Results. Callers of Run
+	// can set them for the labels of the run to be reported along with its output.
+	Labels map[string]string
+	// Arch is the architecture the run was pinned to, it's only set by callers of Run to report it along with the
+	// output
+	Arch string
+	// Duration is the wall clock time spent collecting the output
+	Duration time.Duration
+	// Cursor can be passed to Results to only get newer events, it's only set by Results
+	Cursor string
+}
+
+const (
+	InstanceStateRunning = "running"
+	// InstanceStateStopped is used for instances started by the manager that the runtime doesn't report anymore,
+	// e.g. because they failed or were removed by someone else. The runtime doesn't tell these cases apart.
+	InstanceStateStopped = "stopped"
+)
+
+// ErrInstanceNotFound is returned by InstanceStatus for unknown gadget instances.
+var ErrInstanceNotFound = errors.New("gadget instance not found")
+
+// GadgetInstance describes a gadget instance running on the target system.
+type GadgetInstance struct {
+	ID    string `json:"id"`
+	Image string `json:"image"`
+	State string `json:"state"`
+	// Managed is true if the instance was started by this manager
+	Managed bool `json:"managed"`
+	// Created is when the instance was created, if known
+	Created *time.Time `json:"created,omitempty"`
+	// Nodes are the nodes the instance runs on, empty means all nodes
+	Nodes []string `json:"nodes,omitempty"`
+	// Labels were set with SetInstanceLabels
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ImageInfo identifies the exact gadget a reference resolves to.
+type ImageInfo struct {
+	Image      string `json:"image"`
+	Repository string `json:"repository,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	// Digest is the digest of the image config as reported by the OCI handler
+	Digest  string `json:"digest,omitempty"`
+	Created string `json:"created,omitempty"`
+	// MetadataDigest is the sha256 of the gadget metadata
+	MetadataDigest string `json:"metadataDigest"`
+}
+
+// NewImageInfo returns the ImageInfo of image from its gadget info, requested with the extra info of the OCI handler.
+func NewImageInfo(image string, info *api.GadgetInfo) *ImageInfo {
+	sum := sha256.Sum256(info.Metadata)
+	ii := &ImageInfo{
+		Image:          image,
+		MetadataDigest: "sha256:" + hex.EncodeToString(sum[:]),
+	}
+	if info.ExtraInfo == nil {
+		return ii
+	}
+	value := func(key string) string {
+		if a, ok := info.ExtraInfo.Data[key]; ok && a != nil {
+			return string(a.Content)
+		}
+		return ""
+	}
+	ii.Repository = value("oci.repository")
+	ii.Tag = value("oci.tag")
+	ii.Digest = value("oci.digest")
+	ii.Created = value("oci.created")
+	return ii
+}
+
+// Option configures a GadgetManager.
+type Option func(*gadgetManager)
+
+// WithMaxDetachedInstances limits the number of gadget instances that can run in the background at the same time.
+// Zero, the default, means no limit.
+func WithMaxDetachedInstances(max int) Option {
+	return func(g *gadgetManager) {
+		g.maxDetached = max
+	}
+}
+
+// WithLinuxSocket sets the unix socket path the grpc-linux runtime connects to, instead of the default
+// /var/run/ig/ig.socket. The path must be absolute.
+func WithLinuxSocket(path string) Option {
+	return func(g *gadgetManager) {
+		g.linuxSocket = path
+	}
+}
+
+// WithShowAllFields controls whether the results include the fields gadgets mark as hidden, e.g. internal ones.
+// They are included by default.
+func WithShowAllFields(show bool) Option {
+	return func(g *gadgetManager) {
+		g.showAllFields = show
+	}
+}
+
+// WithInfoCacheTTL sets how long the info of a gadget image returned by GetInfo is reused. Zero, the default, disables
+// the cache.
+func WithInfoCacheTTL(ttl time.Duration) Option {
+	return func(g *gadgetManager) {
+		g.infoCache.ttl = ttl
+	}
+}
+
+// WithDataOperators registers additional data operators that are used for every gadget run, along with the one
+// collecting the output.
+func WithDataOperators(ops ...operators.DataOperator) Option {
+	return func(g *gadgetManager) {
+		g.dataOperators = append(g.dataOperators, ops...)
+	}
+}
+
+// RunOption configures a single Run or RunDetached call.
+type RunOption func(*RunConfig)
+
+// RunConfig holds the options of a Run or RunDetached call. It's exported for other implementations of GadgetManager,
+// e.g. fakes, use NewRunConfig to apply the options.
+type RunConfig struct {
+	// EventCounter is incremented for every event collected by Run, e.g. to report the progress of the run
+	EventCounter *atomic.Int64
+	// Subscriber receives the events of the instance started by RunDetached
+	Subscriber *Subscriber
+	// Ctx is used for the calls made to the runtime before starting the instance, see WithContext
+	Ctx context.Context
+}
+
+func (c RunConfig) ctx() context.Context {
+	if c.Ctx == nil {
+		return context.Background()
+	}
+	return c.Ctx
+}
+
+// NewRunConfig returns the configuration of a call with the given options.
+func NewRunConfig(opts ...RunOption) RunConfig {
+	var cfg RunConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithContext sets the context of the calls RunDetached makes to the runtime before starting the instance, e.g. to
+// reconcile the tracked instances once their limit is reached. It doesn't bound the instance itself.
+func WithContext(ctx context.Context) RunOption {
+	return func(c *RunConfig) {
+		c.Ctx = ctx
+	}
+}
+
+// WithEventCounter makes Run increment counter for every event it collects.
+func WithEventCounter(counter *atomic.Int64) RunOption {
+	return func(c *RunConfig) {
+		c.EventCounter = counter
+	}
+}
+
 type gadgetManager struct {
-	runtime igruntime.Runtime
+	runtime       igruntime.Runtime
+	dataOperators []operators.DataOperator
+	maxDetached   int
+	linuxSocket   string
+	showAllFields bool
+
+	// instances keeps track of the gadget instances started by this manager
+	instances *instanceTracker
+	// runs keeps track of the foreground runs in progress
+	runs runTracker
+	// infoCache keeps the info of gadget images returned by GetInfo
+	infoCache infoCache
 }
 
 // NewGadgetManager creates a new GadgetManager instance.
-func NewGadgetManager(runtime string) (GadgetManager, error) {
+func NewGadgetManager(runtime string, opts ...Option) (GadgetManager, error) {
+	g := &gadgetManager{showAllFields: true}
+	for _, opt := range opts {
+		opt(g)
+	}
+
 	var rt igruntime.Runtime
 	var err error
 	switch runtime {
-	case "grpc-k8s":
+	case RuntimeGrpcK8s:
 		rt, err = newGrpcK8sRuntime()
+	case RuntimeGrpcLinux:
+		rt, err = newGrpcLinuxRuntime(g.linuxSocket)
 	default:
 		return nil, fmt.Errorf("unsupported gadget manager runtime: %s", runtime)
 	}
@@ -69,9 +313,10 @@ func NewGadgetManager(runtime string) (GadgetManager, error) {
 	if err := rt.Init(nil); err != nil {
 		return nil, fmt.Errorf("initializing gadget manager runtime: %w", err)
 	}
-	return &gadgetManager{
-		runtime: rt,
-	}, nil
+	g.runtime = rt
+	g.instances = newInstanceTracker(g.maxDetached)
+	metrics.SetBackgroundInstancesFunc(g.instances.count)
+	return g, nil
 }
 
 func newGrpcK8sRuntime() (igruntime.Runtime, error) {
@@ -88,14 +333,37 @@ func newGrpcK8sRuntime() (igruntime.Runtime, error) {
 	return rt, nil
 }
 
-func (g *gadgetManager) Run(image string, params map[string]string, timeout time.Duration) (string, error) {
+func newGrpcLinuxRuntime(socketPath string) (igruntime.Runtime, error) {
+	environment.Environment = environment.Local
+	rt := grpcruntime.New()
+	p := rt.GlobalParamDescs().ToParams()
+	if socketPath != "" {
+		if !filepath.IsAbs(socketPath) {
+			return nil, fmt.Errorf("linux socket path %q must be absolute", socketPath)
+		}
+		if err := p.Set(grpcruntime.ParamRemoteAddress, "unix://"+socketPath); err != nil {
+			return nil, fmt.Errorf("setting linux socket: %w", err)
+		}
+	}
+	if err := rt.Init(p); err != nil {
+		return nil, fmt.Errorf("initializing grpc gadget manager: %w", err)
+	}
+	return rt, nil
+}
+
+func (g *gadgetManager) Run(image string, params map[string]string, timeout time.Duration, nodes []string, opts ...RunOption) (*RunResult, error) {
 	const opPriority = 50000
+	cfg := NewRunConfig(opts...)
+	var mu sync.Mutex
 	var jsonBuffer []byte
+	var events int
+	var sources []string
+	var eventNodes []string
 	myOperator := simple.New("myOperator",
 		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
 			for _, d := range gadgetCtx.GetDataSources() {
 				jsonFormatter, _ := igjson.New(d,
-					igjson.WithShowAll(true),
+					igjson.WithShowAll(g.showAllFields),
 				)
 
 				// skip data sources that have the annotation "cli.default-output-mode"
@@ -104,10 +372,25 @@ func (g *gadgetManager) Run(image string, params map[string]string, timeout time
 					continue
 				}
 
+				nodeField := d.GetField(nodeFieldName)
 				d.Subscribe(func(source datasource.DataSource, data datasource.Data) error {
 					jsonData := jsonFormatter.Marshal(data)
+					mu.Lock()
+					defer mu.Unlock()
 					jsonBuffer = append(jsonBuffer, jsonData...)
 					jsonBuffer = append(jsonBuffer, '\n')
+					events++
+					if cfg.EventCounter != nil {
+						cfg.EventCounter.Add(1)
+					}
+					if !slices.Contains(sources, source.Name()) {
+						sources = append(sources, source.Name())
+					}
+					if nodeField != nil {
+						if node, err := nodeField.String(data); err == nil && node != "" && !slices.Contains(eventNodes, node) {
+							eventNodes = append(eventNodes, node)
+						}
+					}
 					return nil
 				}, opPriority)
 			}
@@ -115,56 +398,246 @@ func (g *gadgetManager) Run(image string, params map[string]string, timeout time
 		}),
 	)
 
+	p, err := g.runtimeParams(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, done := g.runs.start(image)
+	defer done()
+
+	messages := newMessageCollector()
 	gadgetCtx := gadgetcontext.New(
-		context.Background(),
+		ctx,
 		image,
 		gadgetcontext.WithDataOperators(
-			myOperator,
+			append([]operators.DataOperator{myOperator}, g.dataOperators...)...,
 		),
 		gadgetcontext.WithTimeout(timeout),
+		gadgetcontext.WithLogger(logger.NewFromGenericLogger(messages)),
 	)
 
-	if err := g.runtime.RunGadget(gadgetCtx, nil, params); err != nil {
-		return "", fmt.Errorf("running gadget: %w", err)
+	start := time.Now()
+	err = g.runtime.RunGadget(gadgetCtx, p, params)
+	mu.Lock()
+	defer mu.Unlock()
+	return runResult(&RunResult{
+		Output:      string(jsonBuffer),
+		Events:      events,
+		DataSources: sources,
+		Nodes:       eventNodes,
+		Duration:    time.Since(start),
+		Messages:    messages.list(),
+	}, err, ctx.Err() != nil)
+}
+
+// runResult returns the result of a run that ended with err. A cancelled run returns the output collected so far,
+// whatever the runtime reports, and so does a run that hit a deadline after collecting events. Other errors, or a
+// deadline without any event, fail the run.
+func runResult(res *RunResult, err error, cancelled bool) (*RunResult, error) {
+	res.Cancelled = cancelled
+	switch {
+	case err == nil || cancelled:
+		return res, nil
+	case isDeadlineExceeded(err) && res.Events > 0:
+		log.Debug("Gadget run hit a deadline, returning partial output", "events", res.Events, "error", err)
+		res.TimedOut = true
+		return res, nil
 	}
-	return string(jsonBuffer), nil
+	return nil, fmt.Errorf("running gadget: %w", err)
+}
+
+// isDeadlineExceeded tells whether err is a deadline exceeded, either from a context or from the gRPC backend.
+func isDeadlineExceeded(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded
+}
+
+func (g *gadgetManager) ForegroundRuns() []ForegroundRun {
+	return g.runs.list()
 }
 
-func (g *gadgetManager) RunDetached(image string, params map[string]string) (string, error) {
+func (g *gadgetManager) CancelRun(id string) error {
+	return g.runs.cancel(id)
+}
+
+// runtimeParams returns the runtime params for a run, limited to the given nodes if any.
+func (g *gadgetManager) runtimeParams(nodes []string) (*params.Params, error) {
+	p := g.runtime.ParamDescs().ToParams()
+	if len(nodes) > 0 {
+		// Only the runtime for Kubernetes runs gadgets on several nodes
+		if p.Get(grpcruntime.ParamNode) == nil {
+			return nil, fmt.Errorf("selecting nodes is only supported with the %s runtime", RuntimeGrpcK8s)
+		}
+		if err := p.Set(grpcruntime.ParamNode, strings.Join(nodes, ",")); err != nil {
+			return nil, fmt.Errorf("setting nodes: %w", err)
+		}
+	}
+	return p, nil
+}
+
+func (g *gadgetManager) RunDetached(image string, params map[string]string, nodes []string, opts ...RunOption) (string, error) {
+	cfg := NewRunConfig(opts...)
 	gadgetCtx := gadgetcontext.New(
 		context.Background(),
 		image,
 	)
 
-	p := g.runtime.ParamDescs().ToParams()
+	p, err := g.runtimeParams(nodes)
+	if err != nil {
+		return "", err
+	}
+
+	idString := newID()
 
-	newID := make([]byte, 16)
-	rand.Read(newID)
-	idString := hex.EncodeToString(newID)
+	if g.instances.full() {
+		// Instances that ended or were removed outside of the manager must not count toward the limit
+		if _, err := g.ListInstances(cfg.ctx()); err != nil {
+			log.Debug("Failed to reconcile gadget instances before counting them", "error", err)
+		}
+	}
+	if err := g.instances.add(idString, image); err != nil {
+		return "", err
+	}
 
 	p.Set(grpcruntime.ParamID, idString)
 	p.Set(grpcruntime.ParamDetach, "true")
 	if err := g.runtime.RunGadget(gadgetCtx, p, params); err != nil {
+		g.instances.remove(idString)
 		return "", fmt.Errorf("running gadget: %w", err)
 	}
+	if sub := cfg.Subscriber; sub != nil {
+		// The subscription is cancelled once the instance is stopped with Stop
+		ctx, cancel := context.WithCancel(sub.Ctx)
+		g.instances.setSubscription(idString, cancel)
+		go func() {
+			defer cancel()
+			g.subscribe(ctx, idString, sub)
+		}()
+	}
 	return idString, nil
 }
 
+// instanceManager is implemented by runtimes that support gadget instances, e.g. grpc.
+type instanceManager interface {
+	RemoveGadgetInstance(ctx context.Context, runtimeParams *params.Params, id string) error
+	GetGadgetInstances(ctx context.Context, runtimeParams *params.Params) ([]*api.GadgetInstance, error)
+}
+
+func (g *gadgetManager) instanceManager() (instanceManager, error) {
+	im, ok := g.runtime.(instanceManager)
+	if !ok {
+		return nil, fmt.Errorf("runtime doesn't support gadget instances")
+	}
+	return im, nil
+}
+
 func (g *gadgetManager) Stop(id string) error {
-	if err := g.runtime.(*grpcruntime.Runtime).RemoveGadgetInstance(context.Background(), g.runtime.ParamDescs().ToParams(), id); err != nil {
+	im, err := g.instanceManager()
+	if err != nil {
+		return err
+	}
+	if err := im.RemoveGadgetInstance(context.Background(), g.runtime.ParamDescs().ToParams(), id); err != nil {
 		return fmt.Errorf("stopping to gadget: %w", err)
 	}
+
+	g.instances.remove(id)
+	return nil
+}
+
+func (g *gadgetManager) SetInstanceLabels(id string, labels map[string]string) error {
+	if !g.instances.setLabels(id, labels) {
+		return fmt.Errorf("%w: %s", ErrInstanceNotFound, id)
+	}
+	return nil
+}
+
+func (g *gadgetManager) TailCursor(id string) string {
+	tracked, _ := g.instances.get(id)
+	return tracked.tailCursor
+}
+
+func (g *gadgetManager) SetTailCursor(id, cursor string) error {
+	if !g.instances.setTailCursor(id, cursor) {
+		return fmt.Errorf("%w: %s", ErrInstanceNotFound, id)
+	}
 	return nil
 }
 
-func (g *gadgetManager) Results(id string) (string, error) {
+func (g *gadgetManager) ListInstances(ctx context.Context) ([]GadgetInstance, error) {
+	im, err := g.instanceManager()
+	if err != nil {
+		return nil, err
+	}
+	listed := g.instances.clock()
+	instances, err := im.GetGadgetInstances(ctx, g.runtime.ParamDescs().ToParams())
+	if err != nil {
+		return nil, fmt.Errorf("listing gadget instances: %w", err)
+	}
+	ids := make([]string, 0, len(instances))
+	for _, inst := range instances {
+		ids = append(ids, inst.Id)
+	}
+	g.instances.reconcile(ids, listed)
+
+	res := make([]GadgetInstance, 0, len(instances))
+	for _, inst := range instances {
+		var image string
+		if inst.GadgetConfig != nil {
+			image = inst.GadgetConfig.ImageName
+		}
+		tracked, managed := g.instances.get(inst.Id)
+		instance := GadgetInstance{
+			ID:    inst.Id,
+			Image: image,
+			// The runtime only reports instances that are currently running
+			State:   InstanceStateRunning,
+			Managed: managed,
+			Nodes:   inst.Nodes,
+			Labels:  tracked.labels,
+		}
+		if inst.TimeCreated != 0 {
+			created := time.Unix(inst.TimeCreated, 0)
+			instance.Created = &created
+		}
+		res = append(res, instance)
+	}
+	return res, nil
+}
+
+func (g *gadgetManager) InstanceStatus(ctx context.Context, id string) (*GadgetInstance, error) {
+	instances, err := g.ListInstances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, inst := range instances {
+		if inst.ID == id {
+			return &inst, nil
+		}
+	}
+	if tracked, ok := g.instances.get(id); ok {
+		return &GadgetInstance{
+			ID:      id,
+			Image:   tracked.image,
+			State:   InstanceStateStopped,
+			Managed: true,
+			Created: &tracked.started,
+			Labels:  tracked.labels,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrInstanceNotFound, id)
+}
+
+func (g *gadgetManager) Results(id string, cursor string) (*RunResult, error) {
 	const opPriority = 50000
+	var mu sync.Mutex
 	var jsonBuffer []byte
+	var events int
+	var sources []string
 	myOperator := simple.New("myOperator",
 		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
 			for _, d := range gadgetCtx.GetDataSources() {
 				jsonFormatter, _ := igjson.New(d,
-					igjson.WithShowAll(true),
+					igjson.WithShowAll(g.showAllFields),
 				)
 
 				// skip data sources that have the annotation "cli.default-output-mode"
@@ -175,8 +648,14 @@ func (g *gadgetManager) Results(id string) (string, error) {
 
 				d.Subscribe(func(source datasource.DataSource, data datasource.Data) error {
 					jsonData := jsonFormatter.Marshal(data)
+					mu.Lock()
+					defer mu.Unlock()
 					jsonBuffer = append(jsonBuffer, jsonData...)
 					jsonBuffer = append(jsonBuffer, '\n')
+					events++
+					if !slices.Contains(sources, source.Name()) {
+						sources = append(sources, source.Name())
+					}
 					return nil
 				}, opPriority)
 			}
@@ -191,32 +670,67 @@ func (g *gadgetManager) Results(id string) (string, error) {
 		to,
 		id,
 		gadgetcontext.WithDataOperators(
-			myOperator,
+			append([]operators.DataOperator{myOperator}, g.dataOperators...)...,
 		),
 		gadgetcontext.WithID(id),
 		gadgetcontext.WithUseInstance(true),
 		gadgetcontext.WithTimeout(time.Second),
 	)
 
+	start := time.Now()
 	if err := g.runtime.RunGadget(gadgetCtx, g.runtime.ParamDescs().ToParams(), map[string]string{}); err != nil {
-		return "", fmt.Errorf("attaching to gadget: %w", err)
+		return nil, fmt.Errorf("attaching to gadget: %w", err)
+	}
+	res := &RunResult{
+		Output:      string(jsonBuffer),
+		Events:      events,
+		DataSources: sources,
+		Duration:    time.Since(start),
+	}
+	if tracked, ok := g.instances.get(id); ok {
+		res.Labels = tracked.labels
+	}
+	if err := ApplyCursor(res, cursor); err != nil {
+		return nil, err
 	}
-	return string(jsonBuffer), nil
+	return res, nil
 }
 
 func (g *gadgetManager) GetInfo(ctx context.Context, image string) (*api.GadgetInfo, error) {
+	if info, ok := g.infoCache.get(image); ok {
+		log.DebugContext(ctx, "using cached gadget info", "image", image)
+		return info, nil
+	}
 	gadgetCtx := gadgetcontext.New(
 		ctx,
 		image,
 	)
 
+	start := time.Now()
 	info, err := g.runtime.GetGadgetInfo(gadgetCtx, nil, nil)
+	metrics.ObserveDuration(metrics.GadgetInfoDuration.WithLabelValues(strconv.FormatBool(err == nil)), start)
+	log.DebugContext(ctx, "got gadget info", "image", image, "duration", time.Since(start), "success", err == nil)
 	if err != nil {
 		return nil, fmt.Errorf("get gadget info: %w", err)
 	}
+	g.infoCache.put(image, info)
 	return info, nil
 }
 
+func (g *gadgetManager) ResolveImage(ctx context.Context, image string) (*ImageInfo, error) {
+	gadgetCtx := gadgetcontext.New(
+		ctx,
+		image,
+		gadgetcontext.IncludeExtraInfo(true),
+	)
+
+	info, err := g.runtime.GetGadgetInfo(gadgetCtx, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get gadget info: %w", err)
+	}
+	return NewImageInfo(image, info), nil
+}
+
 func (g *gadgetManager) Close() error {
 	if g.runtime != nil {
 		return g.runtime.Close()
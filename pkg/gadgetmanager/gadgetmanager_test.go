@@ -0,0 +1,386 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	igruntime "github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+	grpcruntime "github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeRuntime uses the param descriptors of the grpc runtime but doesn't connect to any backend.
+type fakeRuntime struct {
+	*grpcruntime.Runtime
+	runs atomic.Int64
+	// err is returned by RunGadget
+	err error
+
+	mu sync.Mutex
+	// instances are the IDs of the detached instances reported by GetGadgetInstances
+	instances []string
+}
+
+func (f *fakeRuntime) RunGadget(gadgetCtx igruntime.GadgetContext, runtimeParams *params.Params, paramValues api.ParamValues) error {
+	f.runs.Add(1)
+	if f.err == nil {
+		if p := runtimeParams.Get(grpcruntime.ParamDetach); p != nil && p.AsBool() {
+			f.mu.Lock()
+			f.instances = append(f.instances, runtimeParams.Get(grpcruntime.ParamID).AsString())
+			f.mu.Unlock()
+		}
+	}
+	return f.err
+}
+
+func (f *fakeRuntime) RemoveGadgetInstance(ctx context.Context, runtimeParams *params.Params, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.instances = slices.DeleteFunc(f.instances, func(i string) bool { return i == id })
+	return nil
+}
+
+func (f *fakeRuntime) GetGadgetInstances(ctx context.Context, runtimeParams *params.Params) ([]*api.GadgetInstance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var instances []*api.GadgetInstance
+	for _, id := range f.instances {
+		instances = append(instances, &api.GadgetInstance{Id: id})
+	}
+	return instances, nil
+}
+
+func newTestManager() (*gadgetManager, *fakeRuntime) {
+	rt := &fakeRuntime{Runtime: grpcruntime.New(grpcruntime.WithConnectUsingK8SProxy)}
+	return &gadgetManager{
+		runtime:   rt,
+		instances: newInstanceTracker(0),
+	}, rt
+}
+
+func TestConcurrentRuns(t *testing.T) {
+	const workers = 50
+	g, rt := newTestManager()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*3)
+	for range workers {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if _, err := g.Run("trace_dns:latest", map[string]string{}, time.Second, nil); err != nil {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			id, err := g.RunDetached("trace_dns:latest", map[string]string{}, nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if err := g.Stop(id); err != nil {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := g.ListInstances(context.Background()); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got := rt.runs.Load(); got != workers*2 {
+		t.Errorf("expected %d runs, got %d", workers*2, got)
+	}
+	if n := g.instances.count(); n != 0 {
+		t.Errorf("expected all instances to be stopped, got %d", n)
+	}
+}
+
+func TestMaxDetachedInstances(t *testing.T) {
+	g, rt := newTestManager()
+	g.instances = newInstanceTracker(1)
+
+	id, err := g.RunDetached("trace_dns:latest", map[string]string{}, nil)
+	if err != nil {
+		t.Fatalf("running first gadget: %v", err)
+	}
+	if _, err := g.RunDetached("trace_dns:latest", map[string]string{}, nil); !errors.Is(err, ErrTooManyInstances) {
+		t.Fatalf("expected ErrTooManyInstances, got %v", err)
+	}
+	if err := g.Stop(id); err != nil {
+		t.Fatalf("stopping gadget: %v", err)
+	}
+	id, err = g.RunDetached("trace_dns:latest", map[string]string{}, nil)
+	if err != nil {
+		t.Errorf("expected a run to be allowed after stopping one, got %v", err)
+	}
+
+	// An instance removed outside of the manager frees its slot once reconciled, and is reported as stopped
+	if err := rt.RemoveGadgetInstance(context.Background(), nil, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.RunDetached("trace_dns:latest", map[string]string{}, nil); err != nil {
+		t.Errorf("expected a run to be allowed after an instance was removed outside of the manager, got %v", err)
+	}
+	inst, err := g.InstanceStatus(context.Background(), id)
+	if err != nil || inst.State != InstanceStateStopped {
+		t.Errorf("expected the removed instance to be stopped, got %+v, %v", inst, err)
+	}
+}
+
+func TestInstanceTrackerReconcile(t *testing.T) {
+	tr := newInstanceTracker(0)
+	now := time.Unix(0, 0)
+	tr.clock = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	for i := range maxStoppedInstances + 2 {
+		if err := tr.add(fmt.Sprintf("%d", i), "trace_dns:latest"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Instances started when listing are considered, the ones started after it aren't
+	listed := now
+	if err := tr.add("new", "trace_dns:latest"); err != nil {
+		t.Fatal(err)
+	}
+	tr.setTailCursor("1", "t1:1:1")
+	tr.reconcile([]string{"0"}, listed)
+	if n := tr.count(); n != 2 {
+		t.Errorf("expected 2 running instances, got %d", n)
+	}
+	if len(tr.instances) != maxStoppedInstances+2 {
+		t.Errorf("expected the stopped instances to be bounded, got %d instances", len(tr.instances))
+	}
+	// The oldest stopped instance is dropped along with its tail cursor
+	if inst, ok := tr.get("1"); ok || inst.tailCursor != "" {
+		t.Errorf("expected the tail cursor of the dropped instance to be forgotten, got %q", inst.tailCursor)
+	}
+}
+
+func TestRunDetachedSubscriber(t *testing.T) {
+	g, rt := newTestManager()
+	done := make(chan error, 1)
+	id, err := g.RunDetached("trace_dns:latest", map[string]string{}, nil,
+		WithSubscriber(context.Background(), func(string) {}, func(err error) { done <- err }))
+	if err != nil {
+		t.Fatalf("running gadget: %v", err)
+	}
+	select {
+	case err := <-done:
+		// The fake runtime returns right away, as if the instance ended
+		if err != nil {
+			t.Errorf("unexpected subscription error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the subscription to end")
+	}
+	if rt.runs.Load() != 2 {
+		t.Errorf("expected the instance to be started and attached, got %d runs", rt.runs.Load())
+	}
+
+	// Stopping the instance ends its subscription
+	ctx, cancel := context.WithCancel(context.Background())
+	g.instances.setSubscription(id, cancel)
+	if err := g.Stop(id); err != nil {
+		t.Fatalf("stopping gadget: %v", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("expected the subscription to be cancelled on stop")
+	}
+}
+
+func TestRuntimeParamsNodes(t *testing.T) {
+	g, _ := newTestManager()
+	p, err := g.runtimeParams([]string{"node-1", "node-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := p.Get(grpcruntime.ParamNode).AsString(); got != "node-1,node-2" {
+		t.Errorf("expected the nodes to be set, got %q", got)
+	}
+
+	g.runtime = &fakeRuntime{Runtime: grpcruntime.New()}
+	if _, err := g.runtimeParams([]string{"node-1"}); err == nil {
+		t.Error("expected nodes to be rejected by a runtime without the node param")
+	}
+	if _, err := g.runtimeParams(nil); err != nil {
+		t.Errorf("unexpected error without nodes: %v", err)
+	}
+}
+
+func TestApplyCursor(t *testing.T) {
+	output := "{\"timestamp\":\"2025-01-01T00:00:01Z\",\"n\":1}\n" +
+		"{\"timestamp\":\"2025-01-01T00:00:02Z\",\"n\":2}\n" +
+		"{\"n\":3}\n"
+
+	res := &RunResult{Output: output}
+	if err := ApplyCursor(res, ""); err != nil {
+		t.Fatalf("applying empty cursor: %v", err)
+	}
+	if res.Events != 3 || res.Cursor == "" {
+		t.Fatalf("expected all events and a cursor, got %d events and cursor %q", res.Events, res.Cursor)
+	}
+
+	cursor := res.Cursor
+	res = &RunResult{Output: output + "{\"timestamp\":\"2025-01-01T00:00:03Z\",\"n\":4}\n"}
+	if err := ApplyCursor(res, cursor); err != nil {
+		t.Fatalf("applying cursor: %v", err)
+	}
+	if res.Output != "{\"n\":3}\n{\"timestamp\":\"2025-01-01T00:00:03Z\",\"n\":4}\n" {
+		t.Errorf("expected only new events and the ones without timestamp, got %q", res.Output)
+	}
+
+	if err := ApplyCursor(&RunResult{}, "invalid"); err == nil {
+		t.Errorf("expected an error for an invalid cursor")
+	}
+}
+
+func TestCursorSameTimestamp(t *testing.T) {
+	output := "{\"timestamp\":\"2025-01-01T00:00:01Z\",\"n\":1}\n" +
+		"{\"timestamp\":\"2025-01-01T00:00:02Z\",\"n\":2}\n" +
+		"{\"timestamp\":\"2025-01-01T00:00:02Z\",\"n\":3}\n" +
+		"{\"timestamp\":\"2025-01-01T00:00:02Z\",\"n\":4}\n"
+
+	// Only the first two events were returned, e.g. because the rest was truncated
+	cursor, err := NextCursor("", EventLines(output)[:2])
+	if err != nil {
+		t.Fatalf("getting next cursor: %v", err)
+	}
+	res := &RunResult{Output: output}
+	if err := ApplyCursor(res, cursor); err != nil {
+		t.Fatalf("applying cursor: %v", err)
+	}
+	if res.Output != "{\"timestamp\":\"2025-01-01T00:00:02Z\",\"n\":3}\n{\"timestamp\":\"2025-01-01T00:00:02Z\",\"n\":4}\n" {
+		t.Errorf("expected the events sharing the timestamp of the cursor that weren't returned, got %q", res.Output)
+	}
+
+	cursor = res.Cursor
+	res = &RunResult{Output: output}
+	if err := ApplyCursor(res, cursor); err != nil {
+		t.Fatalf("applying cursor: %v", err)
+	}
+	if res.Output != "" || res.Cursor != cursor {
+		t.Errorf("expected no new events and the same cursor, got %q and cursor %q", res.Output, res.Cursor)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	deadlineErr := status.Error(codes.DeadlineExceeded, "context deadline exceeded")
+
+	// No data collected before the deadline
+	g, rt := newTestManager()
+	rt.err = deadlineErr
+	if _, err := g.Run("trace_dns:latest", map[string]string{}, time.Second, nil); err == nil {
+		t.Errorf("expected an error for a timeout without data")
+	}
+
+	// Timeout mid-stream, after some events were collected
+	partial := func() *RunResult {
+		return &RunResult{Output: "{\"comm\":\"curl\"}\n{\"comm\":\"wget\"}\n", Events: 2, DataSources: []string{"dns"}}
+	}
+	for _, err := range []error{deadlineErr, fmt.Errorf("running: %w", context.DeadlineExceeded)} {
+		res, err := runResult(partial(), err, false)
+		if err != nil {
+			t.Fatalf("expected the partial output, got error %v", err)
+		}
+		if !res.TimedOut || res.Events != 2 || res.Output != partial().Output {
+			t.Errorf("expected the partial output to be returned as timed out, got %+v", res)
+		}
+	}
+
+	// Other errors fail the run even with data
+	if _, err := runResult(partial(), errors.New("connection reset"), false); err == nil {
+		t.Errorf("expected an error for a failed run")
+	}
+	// A cancelled run returns its data whatever the error
+	res, err := runResult(partial(), errors.New("connection reset"), true)
+	if err != nil || !res.Cancelled || res.TimedOut {
+		t.Errorf("expected a cancelled result, got %+v, %v", res, err)
+	}
+}
+
+func TestMessageCollector(t *testing.T) {
+	c := newMessageCollector()
+	l := logger.NewFromGenericLogger(c)
+	if l.GetLevel() != logger.WarnLevel {
+		t.Errorf("expected the warn level to be requested from the backend, got %s", l.GetLevel())
+	}
+	l.Infof("node-1 | starting")
+	l.Warnf("node-1 | map full, %d events dropped", 10)
+	l.Log(logger.ErrorLevel, "node-2 | failed to attach")
+	expected := []string{"warning: node-1 | map full, 10 events dropped", "error: node-2 | failed to attach"}
+	if got := c.list(); !slices.Equal(got, expected) {
+		t.Errorf("expected messages %q, got %q", expected, got)
+	}
+
+	for range maxRunMessages {
+		l.Warn("again")
+	}
+	got := c.list()
+	if len(got) != maxRunMessages+1 || got[maxRunMessages] != "2 more messages not shown" {
+		t.Errorf("expected the messages to be limited, got %q", got)
+	}
+}
+
+func TestInfoCache(t *testing.T) {
+	info := &api.GadgetInfo{ImageName: "trace_dns:latest"}
+	c := infoCache{ttl: time.Minute}
+	if _, ok := c.get("trace_dns:latest"); ok {
+		t.Fatal("expected an empty cache")
+	}
+	c.put("trace_dns:latest", info)
+	if got, ok := c.get("trace_dns:latest"); !ok || got != info {
+		t.Errorf("expected the cached info, got %v, %v", got, ok)
+	}
+
+	c.entries["trace_dns:latest"] = infoCacheEntry{info: info, fetched: time.Now().Add(-2 * time.Minute)}
+	if _, ok := c.get("trace_dns:latest"); ok {
+		t.Error("expected an expired entry to be dropped")
+	}
+
+	c.entries["trace_exec:latest"] = infoCacheEntry{info: info, fetched: time.Now().Add(-2 * time.Minute)}
+	c.put("trace_dns:latest", info)
+	if _, ok := c.entries["trace_exec:latest"]; ok {
+		t.Error("expected expired entries to be evicted on put")
+	}
+
+	disabled := infoCache{}
+	disabled.put("trace_dns:latest", info)
+	if _, ok := disabled.get("trace_dns:latest"); ok {
+		t.Error("expected a disabled cache to not keep the info")
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gadgetmanagertest provides a fake GadgetManager to test code depending on it without a cluster.
+package gadgetmanagertest
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+// RunCall records the arguments of a Run or RunDetached call.
+type RunCall struct {
+	Image   string
+	Params  map[string]string
+	Timeout time.Duration
+	Nodes   []string
+}
+
+// FakeGadgetManager is an in-memory GadgetManager. Its exported fields configure the responses and record the
+// calls; they must not be changed while the manager is in use.
+type FakeGadgetManager struct {
+	// Infos maps gadget images to the info returned by GetInfo
+	Infos map[string]*api.GadgetInfo
+	// Result is returned by Run and Results, the latter leaves out the events before its cursor if one is given
+	Result *gadgetmanager.RunResult
+	// Err is returned by all methods if set
+	Err error
+
+	mu            sync.Mutex
+	runCalls      []RunCall
+	detachedCalls []RunCall
+	instances     map[string]string
+	labels        map[string]map[string]string
+	tailCursors   map[string]string
+}
+
+var _ gadgetmanager.GadgetManager = (*FakeGadgetManager)(nil)
+
+// NewFakeGadgetManager creates a FakeGadgetManager returning the given infos.
+func NewFakeGadgetManager(infos ...*api.GadgetInfo) *FakeGadgetManager {
+	f := &FakeGadgetManager{
+		Infos:       make(map[string]*api.GadgetInfo),
+		Result:      &gadgetmanager.RunResult{},
+		instances:   make(map[string]string),
+		labels:      make(map[string]map[string]string),
+		tailCursors: make(map[string]string),
+	}
+	for _, info := range infos {
+		f.Infos[info.ImageName] = info
+	}
+	return f
+}
+
+// ResolveImage returns the ImageInfo of the info of image.
+func (f *FakeGadgetManager) ResolveImage(ctx context.Context, image string) (*gadgetmanager.ImageInfo, error) {
+	info, err := f.GetInfo(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	return gadgetmanager.NewImageInfo(image, info), nil
+}
+
+// RunCalls returns the recorded Run calls.
+func (f *FakeGadgetManager) RunCalls() []RunCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]RunCall(nil), f.runCalls...)
+}
+
+// DetachedCalls returns the recorded RunDetached calls.
+func (f *FakeGadgetManager) DetachedCalls() []RunCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]RunCall(nil), f.detachedCalls...)
+}
+
+func (f *FakeGadgetManager) Run(image string, params map[string]string, timeout time.Duration, nodes []string, opts ...gadgetmanager.RunOption) (*gadgetmanager.RunResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.runCalls = append(f.runCalls, RunCall{Image: image, Params: maps.Clone(params), Timeout: timeout, Nodes: slices.Clone(nodes)})
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	res := *f.Result
+	if cfg := gadgetmanager.NewRunConfig(opts...); cfg.EventCounter != nil {
+		cfg.EventCounter.Add(int64(res.Events))
+	}
+	return &res, nil
+}
+
+// RunDetached records the call and starts a fake instance. With WithSubscriber, the subscriber gets the events of
+// Result, then the subscription ends as if the instance ended.
+func (f *FakeGadgetManager) RunDetached(image string, params map[string]string, nodes []string, opts ...gadgetmanager.RunOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.detachedCalls = append(f.detachedCalls, RunCall{Image: image, Params: maps.Clone(params), Nodes: slices.Clone(nodes)})
+	if f.Err != nil {
+		return "", f.Err
+	}
+	id := fmt.Sprintf("%032x", len(f.detachedCalls))
+	f.instances[id] = image
+	if sub := gadgetmanager.NewRunConfig(opts...).Subscriber; sub != nil {
+		output := f.Result.Output
+		go func() {
+			for _, event := range gadgetmanager.EventLines(output) {
+				sub.OnEvent(event)
+			}
+			sub.OnDone(nil)
+		}()
+	}
+	return id, nil
+}
+
+func (f *FakeGadgetManager) Results(id string, cursor string) (*gadgetmanager.RunResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if _, ok := f.instances[id]; !ok {
+		return nil, fmt.Errorf("gadget instance %s not found", id)
+	}
+	res := *f.Result
+	if cursor != "" {
+		if err := gadgetmanager.ApplyCursor(&res, cursor); err != nil {
+			return nil, err
+		}
+	}
+	return &res, nil
+}
+
+func (f *FakeGadgetManager) Stop(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, ok := f.instances[id]; !ok {
+		return fmt.Errorf("gadget instance %s not found", id)
+	}
+	delete(f.instances, id)
+	delete(f.labels, id)
+	delete(f.tailCursors, id)
+	return nil
+}
+
+func (f *FakeGadgetManager) ListInstances(ctx context.Context) ([]gadgetmanager.GadgetInstance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	var instances []gadgetmanager.GadgetInstance
+	for id, image := range f.instances {
+		instances = append(instances, gadgetmanager.GadgetInstance{ID: id, Image: image, State: gadgetmanager.InstanceStateRunning, Managed: true, Labels: f.labels[id]})
+	}
+	return instances, nil
+}
+
+func (f *FakeGadgetManager) InstanceStatus(ctx context.Context, id string) (*gadgetmanager.GadgetInstance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	image, ok := f.instances[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", gadgetmanager.ErrInstanceNotFound, id)
+	}
+	return &gadgetmanager.GadgetInstance{ID: id, Image: image, State: gadgetmanager.InstanceStateRunning, Managed: true, Labels: f.labels[id]}, nil
+}
+
+// SetInstanceLabels sets the labels reported for an instance started by RunDetached.
+func (f *FakeGadgetManager) SetInstanceLabels(id string, labels map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.instances[id]; !ok {
+		return fmt.Errorf("%w: %s", gadgetmanager.ErrInstanceNotFound, id)
+	}
+	f.labels[id] = labels
+	return nil
+}
+
+// TailCursor returns the cursor stored with SetTailCursor.
+func (f *FakeGadgetManager) TailCursor(id string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tailCursors[id]
+}
+
+// SetTailCursor stores the cursor of an instance started by RunDetached, it's forgotten by Stop.
+func (f *FakeGadgetManager) SetTailCursor(id, cursor string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.instances[id]; !ok {
+		return fmt.Errorf("%w: %s", gadgetmanager.ErrInstanceNotFound, id)
+	}
+	f.tailCursors[id] = cursor
+	return nil
+}
+
+func (f *FakeGadgetManager) GetInfo(ctx context.Context, image string) (*api.GadgetInfo, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	info, ok := f.Infos[image]
+	if !ok {
+		return nil, fmt.Errorf("gadget image %s not found", image)
+	}
+	return info, nil
+}
+
+func (f *FakeGadgetManager) Close() error {
+	return nil
+}
+
+func (f *FakeGadgetManager) ForegroundRuns() []gadgetmanager.ForegroundRun {
+	return nil
+}
+
+func (f *FakeGadgetManager) CancelRun(id string) error {
+	return fmt.Errorf("no run in progress with ID %s", id)
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetmanager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+type infoCacheEntry struct {
+	info    *api.GadgetInfo
+	fetched time.Time
+}
+
+// infoCache keeps the info of gadget images for ttl, a ttl of zero (the default) disables it. Images referenced by a
+// tag can be updated or Inspektor Gadget redeployed meanwhile, so it's disabled by default. It is safe for concurrent
+// use.
+type infoCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]infoCacheEntry
+}
+
+func (c *infoCache) get(image string) (*api.GadgetInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[image]
+	if !ok {
+		return nil, false
+	}
+	if time.Since(entry.fetched) >= c.ttl {
+		delete(c.entries, image)
+		return nil, false
+	}
+	return entry.info, true
+}
+
+func (c *infoCache) put(image string, info *api.GadgetInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ttl <= 0 {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]infoCacheEntry)
+	}
+	now := time.Now()
+	// Evict the expired entries, they are otherwise only dropped when they are looked up
+	for img, entry := range c.entries {
+		if now.Sub(entry.fetched) >= c.ttl {
+			delete(c.entries, img)
+		}
+	}
+	c.entries[image] = infoCacheEntry{info: info, fetched: now}
+}
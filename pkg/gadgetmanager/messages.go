@@ -0,0 +1,86 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetmanager
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+// maxRunMessages is the number of gadget log messages kept for a run
+const maxRunMessages = 20
+
+// messageCollector is a gadget context logger collecting the warnings and errors logged during a run, e.g. by the
+// gadget on the nodes or by the runtime when events are dropped. Other messages are only logged at debug level.
+type messageCollector struct {
+	mu       sync.Mutex
+	level    logger.Level
+	messages []string
+	dropped  int
+}
+
+func newMessageCollector() *messageCollector {
+	// The level is sent to the backend, which then only forwards the messages of that level or higher
+	return &messageCollector{level: logger.WarnLevel}
+}
+
+func (c *messageCollector) Log(severity logger.Level, params ...any) {
+	c.add(severity, fmt.Sprint(params...))
+}
+
+func (c *messageCollector) Logf(severity logger.Level, format string, params ...any) {
+	c.add(severity, fmt.Sprintf(format, params...))
+}
+
+func (c *messageCollector) SetLevel(level logger.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.level = level
+}
+
+func (c *messageCollector) GetLevel() logger.Level {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.level
+}
+
+func (c *messageCollector) add(severity logger.Level, msg string) {
+	log.Debug("Gadget log message", "severity", severity.String(), "message", msg)
+	// Lower levels are more severe
+	if severity > logger.WarnLevel {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.messages) >= maxRunMessages {
+		c.dropped++
+		return
+	}
+	c.messages = append(c.messages, fmt.Sprintf("%s: %s", severity, strings.TrimSpace(msg)))
+}
+
+// list returns the collected messages, noting the ones over maxRunMessages.
+func (c *messageCollector) list() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	messages := append([]string(nil), c.messages...)
+	if c.dropped > 0 {
+		messages = append(messages, fmt.Sprintf("%d more messages not shown", c.dropped))
+	}
+	return messages
+}
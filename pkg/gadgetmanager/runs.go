@@ -0,0 +1,93 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetmanager
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ForegroundRun describes a Run call in progress.
+type ForegroundRun struct {
+	ID      string    `json:"id"`
+	Image   string    `json:"image"`
+	Started time.Time `json:"started"`
+}
+
+type foregroundRun struct {
+	ForegroundRun
+	cancel context.CancelFunc
+}
+
+// runTracker keeps track of the Run calls in progress so they can be cancelled. It is safe for concurrent use.
+type runTracker struct {
+	mu   sync.Mutex
+	runs map[string]*foregroundRun
+}
+
+// start registers a new run and returns its context, done must be called once the run finished.
+func (t *runTracker) start(image string) (ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	id := newID()
+	t.mu.Lock()
+	if t.runs == nil {
+		t.runs = make(map[string]*foregroundRun)
+	}
+	t.runs[id] = &foregroundRun{
+		ForegroundRun: ForegroundRun{ID: id, Image: image, Started: time.Now()},
+		cancel:        cancel,
+	}
+	t.mu.Unlock()
+	return ctx, func() {
+		t.mu.Lock()
+		delete(t.runs, id)
+		t.mu.Unlock()
+		cancel()
+	}
+}
+
+func (t *runTracker) cancel(id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	run, ok := t.runs[id]
+	if !ok {
+		return fmt.Errorf("no run in progress with ID %s", id)
+	}
+	run.cancel()
+	return nil
+}
+
+func (t *runTracker) list() []ForegroundRun {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	runs := make([]ForegroundRun, 0, len(t.runs))
+	for _, run := range t.runs {
+		runs = append(runs, run.ForegroundRun)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Started.Before(runs[j].Started) })
+	return runs
+}
+
+// newID returns a random ID in the format used by Inspektor Gadget for gadget instances.
+func newID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
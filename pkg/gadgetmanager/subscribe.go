@@ -0,0 +1,85 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetmanager
+
+import (
+	"context"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/datasource"
+	igjson "github.com/inspektor-gadget/inspektor-gadget/pkg/datasource/formatters/json"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators/simple"
+)
+
+// Subscriber receives the events of a gadget instance as they are emitted by its data sources, see WithSubscriber.
+type Subscriber struct {
+	// Ctx ends the subscription when it's done
+	Ctx context.Context
+	// OnEvent is called with every event as JSON, it must not block
+	OnEvent func(event string)
+	// OnDone is called once the subscription ended, because Ctx is done, the instance was stopped with Stop or it
+	// ended on its own. err is only set in the last case if the runtime reported an error.
+	OnDone func(err error)
+}
+
+// WithSubscriber makes RunDetached subscribe to the data sources of the started instance, calling onEvent with its
+// events until ctx is done or the instance is stopped or ends, then onDone.
+func WithSubscriber(ctx context.Context, onEvent func(event string), onDone func(err error)) RunOption {
+	return func(c *RunConfig) {
+		c.Subscriber = &Subscriber{Ctx: ctx, OnEvent: onEvent, OnDone: onDone}
+	}
+}
+
+// subscribe attaches to the gadget instance with the given ID and passes its events to sub until ctx is done or the
+// instance ends.
+func (g *gadgetManager) subscribe(ctx context.Context, id string, sub *Subscriber) {
+	const opPriority = 50000
+	subOperator := simple.New("subscriber",
+		simple.OnInit(func(gadgetCtx operators.GadgetContext) error {
+			for _, d := range gadgetCtx.GetDataSources() {
+				jsonFormatter, _ := igjson.New(d,
+					igjson.WithShowAll(g.showAllFields),
+				)
+				if m, ok := d.Annotations()["cli.default-output-mode"]; ok && m == "none" {
+					continue
+				}
+				d.Subscribe(func(source datasource.DataSource, data datasource.Data) error {
+					sub.OnEvent(string(jsonFormatter.Marshal(data)))
+					return nil
+				}, opPriority)
+			}
+			return nil
+		}),
+	)
+
+	gadgetCtx := gadgetcontext.New(
+		ctx,
+		id,
+		gadgetcontext.WithDataOperators(
+			append([]operators.DataOperator{subOperator}, g.dataOperators...)...,
+		),
+		gadgetcontext.WithID(id),
+		gadgetcontext.WithUseInstance(true),
+	)
+	err := g.runtime.RunGadget(gadgetCtx, g.runtime.ParamDescs().ToParams(), map[string]string{})
+	if ctx.Err() != nil {
+		err = nil
+	}
+	if err != nil {
+		log.Debug("Subscription to gadget instance ended", "id", id, "error", err)
+	}
+	sub.OnDone(err)
+}
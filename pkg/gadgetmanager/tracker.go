@@ -0,0 +1,182 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gadgetmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrTooManyInstances is returned by RunDetached when the limit of tracked instances is reached.
+var ErrTooManyInstances = errors.New("too many gadget instances running in the background")
+
+type trackedInstance struct {
+	image   string
+	started time.Time
+	labels  map[string]string
+	// tailCursor is the cursor of the results last followed, see SetTailCursor
+	tailCursor string
+	// unsubscribe ends the subscription to the events of the instance, if any, see WithSubscriber
+	unsubscribe context.CancelFunc
+	// stopped is set once the runtime doesn't report the instance anymore, see reconcile
+	stopped bool
+}
+
+// maxStoppedInstances bounds the number of stopped instances kept to report their state, the oldest are dropped
+const maxStoppedInstances = 100
+
+// instanceTracker keeps track of the detached gadget instances started by the manager. It is safe for concurrent use.
+type instanceTracker struct {
+	mu sync.Mutex
+	// max is the maximum number of tracked instances, 0 means no limit
+	max       int
+	instances map[string]trackedInstance
+	// clock returns the current time, it's replaced in tests
+	clock func() time.Time
+}
+
+func newInstanceTracker(max int) *instanceTracker {
+	return &instanceTracker{
+		max:       max,
+		instances: make(map[string]trackedInstance),
+		clock:     time.Now,
+	}
+}
+
+// add tracks a new instance. It's meant to be called before the instance is started so that concurrent calls can't
+// exceed the limit, and the instance has to be removed if starting it fails.
+func (t *instanceTracker) add(id, image string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.max > 0 && t.running() >= t.max {
+		return fmt.Errorf("%w: the limit is %d, stop some of them first", ErrTooManyInstances, t.max)
+	}
+	t.instances[id] = trackedInstance{image: image, started: t.clock()}
+	return nil
+}
+
+func (t *instanceTracker) remove(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if inst, ok := t.instances[id]; ok && inst.unsubscribe != nil {
+		inst.unsubscribe()
+	}
+	delete(t.instances, id)
+}
+
+func (t *instanceTracker) get(id string) (trackedInstance, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	inst, ok := t.instances[id]
+	return inst, ok
+}
+
+// setLabels sets the labels of a tracked instance, returning false if it isn't tracked.
+func (t *instanceTracker) setLabels(id string, labels map[string]string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	inst, ok := t.instances[id]
+	if !ok {
+		return false
+	}
+	inst.labels = labels
+	t.instances[id] = inst
+	return true
+}
+
+// setTailCursor sets the tail cursor of a tracked instance, returning false if it isn't tracked.
+func (t *instanceTracker) setTailCursor(id, cursor string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	inst, ok := t.instances[id]
+	if !ok {
+		return false
+	}
+	inst.tailCursor = cursor
+	t.instances[id] = inst
+	return true
+}
+
+// setSubscription sets the function ending the subscription to the events of a tracked instance, it's called on
+// remove.
+func (t *instanceTracker) setSubscription(id string, unsubscribe context.CancelFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	inst, ok := t.instances[id]
+	if !ok {
+		return
+	}
+	inst.unsubscribe = unsubscribe
+	t.instances[id] = inst
+}
+
+// count returns the number of tracked instances that are still running, it is exported as the
+// ig_mcp_background_instances metric.
+func (t *instanceTracker) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.running()
+}
+
+// full reports whether the limit of running instances is reached.
+func (t *instanceTracker) full() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.max > 0 && t.running() >= t.max
+}
+
+func (t *instanceTracker) running() int {
+	n := 0
+	for _, inst := range t.instances {
+		if !inst.stopped {
+			n++
+		}
+	}
+	return n
+}
+
+// reconcile marks the tracked instances that aren't in running as stopped, e.g. because they failed or were removed
+// outside of the manager, so that they don't count toward the limit anymore. Only instances started by since, the time
+// running was listed, are considered, as the others may not have been reported yet.
+func (t *instanceTracker) reconcile(running []string, since time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var stopped []string
+	for id, inst := range t.instances {
+		if !inst.stopped && !inst.started.After(since) && !slices.Contains(running, id) {
+			inst.stopped = true
+			if inst.unsubscribe != nil {
+				inst.unsubscribe()
+			}
+			t.instances[id] = inst
+		}
+		if inst.stopped {
+			stopped = append(stopped, id)
+		}
+	}
+	if len(stopped) <= maxStoppedInstances {
+		return
+	}
+	slices.SortFunc(stopped, func(a, b string) int {
+		return t.instances[a].started.Compare(t.instances[b].started)
+	})
+	for _, id := range stopped[:len(stopped)-maxStoppedInstances] {
+		delete(t.instances, id)
+	}
+}
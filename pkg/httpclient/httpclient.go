@@ -0,0 +1,74 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the timeout used for outbound requests that need one.
+	DefaultTimeout = 5 * time.Second
+	// maxIdleConnsPerHost allows reusing connections for bursts of requests against the same host e.g. Artifact Hub
+	maxIdleConnsPerHost = 10
+)
+
+// New creates a new HTTP client with the given timeout that sends userAgent with every request. The client keeps
+// idle connections around, so it should be reused across requests. A zero timeout means no timeout. Requests go
+// through the proxy set with the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables, if any.
+func New(timeout time.Duration, userAgent string) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &userAgentTransport{
+			base:      newTransport(),
+			userAgent: userAgent,
+		},
+	}
+}
+
+// newTransport clones the default transport, which can have been replaced by a dependency, falling back to a new
+// one. The proxy is set explicitly so that it doesn't depend on the default transport.
+func newTransport() *http.Transport {
+	var transport *http.Transport
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport = t.Clone()
+	} else {
+		transport = &http.Transport{
+			ForceAttemptHTTP2:     true,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+	}
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	return transport
+}
+
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent == "" {
+		return t.base.RoundTrip(req)
+	}
+	// RoundTrip must not modify the original request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserAgent(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+	req.Header.Set("User-Agent", "original")
+
+	resp, err := New(DefaultTimeout, "ig-mcp-server/test").Do(req)
+	if err != nil {
+		t.Fatalf("doing request: %v", err)
+	}
+	resp.Body.Close()
+
+	if got != "ig-mcp-server/test" {
+		t.Errorf("expected user-agent %q, got %q", "ig-mcp-server/test", got)
+	}
+	if ua := req.Header.Get("User-Agent"); ua != "original" {
+		t.Errorf("caller's request was modified: user-agent %q", ua)
+	}
+}
+
+func TestProxyFromEnvironment(t *testing.T) {
+	transport := New(DefaultTimeout, "").Transport.(*userAgentTransport).base.(*http.Transport)
+	if transport.Proxy == nil {
+		t.Errorf("expected the transport to use the proxy from the environment")
+	}
+
+	// Dependencies can replace the default transport, e.g. for instrumentation
+	orig := http.DefaultTransport
+	defer func() { http.DefaultTransport = orig }()
+	http.DefaultTransport = roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, nil })
+	transport = New(DefaultTimeout, "").Transport.(*userAgentTransport).base.(*http.Transport)
+	if transport.Proxy == nil || transport.MaxIdleConnsPerHost != maxIdleConnsPerHost {
+		t.Errorf("expected a configured transport when the default one was replaced")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
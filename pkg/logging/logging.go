@@ -0,0 +1,56 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging provides loggers that follow the default slog logger, even if it's set after they are created.
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Default returns a logger that uses the handler of slog.Default() at the time a record is logged. Package level
+// loggers are created before main can configure the default logger e.g. to use JSON, so they can't capture its
+// handler on creation as slog.Default().With(...) does.
+func Default() *slog.Logger {
+	return slog.New(deferredHandler{wrap: func(h slog.Handler) slog.Handler { return h }})
+}
+
+type deferredHandler struct {
+	// wrap applies the attributes and groups added to the logger to the default handler
+	wrap func(slog.Handler) slog.Handler
+}
+
+func (h deferredHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, level)
+}
+
+func (h deferredHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	return h.wrap(slog.Default().Handler()).Handle(ctx, record)
+}
+
+func (h deferredHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return deferredHandler{wrap: func(base slog.Handler) slog.Handler {
+		return h.wrap(base).WithAttrs(attrs)
+	}}
+}
+
+func (h deferredHandler) WithGroup(name string) slog.Handler {
+	return deferredHandler{wrap: func(base slog.Handler) slog.Handler {
+		return h.wrap(base).WithGroup(name)
+	}}
+}
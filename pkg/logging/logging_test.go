@@ -0,0 +1,72 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestDefaultFollowsSlogDefault(t *testing.T) {
+	log := Default().With("component", "test")
+
+	orig := slog.Default()
+	defer slog.SetDefault(orig)
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	log.Info("not logged")
+	log.Warn("logged", "key", "value")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a single JSON record, got %q: %v", buf.String(), err)
+	}
+	if record["msg"] != "logged" || record["component"] != "test" || record["key"] != "value" {
+		t.Errorf("unexpected record: %v", record)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	log := Default().With("component", "test")
+
+	orig := slog.Default()
+	defer slog.SetDefault(orig)
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	id := NewRequestID()
+	if len(id) != 16 || id == NewRequestID() {
+		t.Fatalf("expected a random 16 characters ID, got %q", id)
+	}
+	log.InfoContext(WithRequestID(context.Background(), id), "with ID")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a single JSON record, got %q: %v", buf.String(), err)
+	}
+	if record[RequestIDKey] != id || record["component"] != "test" {
+		t.Errorf("unexpected record: %v", record)
+	}
+
+	buf.Reset()
+	log.Info("without ID")
+	if bytes.Contains(buf.Bytes(), []byte(RequestIDKey)) {
+		t.Errorf("expected no request ID, got %q", buf.String())
+	}
+}
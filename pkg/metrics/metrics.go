@@ -0,0 +1,78 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics holds the Prometheus metrics of the server, they are served by Handler, see -metrics-address.
+package metrics
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// registry only holds the metrics of the server, not the Go runtime ones of the default registry
+var registry = prometheus.NewRegistry()
+
+var (
+	// DiscoveryDuration records how long listing the gadget images takes, by discoverer.
+	DiscoveryDuration = newHistogramVec("ig_mcp_discovery_duration_seconds",
+		"Duration of listing the available gadget images", "discoverer")
+	// GadgetInfoDuration records how long getting the info of a gadget image takes, by whether it succeeded.
+	GadgetInfoDuration = newHistogramVec("ig_mcp_gadget_info_duration_seconds",
+		"Duration of getting the info of a gadget image", "success")
+)
+
+// backgroundInstances returns the number of gadget instances running in the background, see
+// SetBackgroundInstancesFunc
+var backgroundInstances atomic.Pointer[func() int]
+
+func init() {
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "ig_mcp_background_instances",
+		Help: "Number of gadget instances started by the server running in the background",
+	}, func() float64 {
+		if f := backgroundInstances.Load(); f != nil {
+			return float64((*f)())
+		}
+		return 0
+	}))
+}
+
+// SetBackgroundInstancesFunc sets the function reporting the number of gadget instances running in the background.
+func SetBackgroundInstancesFunc(f func() int) {
+	backgroundInstances.Store(&f)
+}
+
+// ObserveDuration records the time elapsed since start in seconds in h.
+func ObserveDuration(h prometheus.Observer, start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+func newHistogramVec(name, help string, labels ...string) *prometheus.HistogramVec {
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    name,
+		Help:    help,
+		Buckets: prometheus.DefBuckets,
+	}, labels)
+	registry.MustRegister(h)
+	return h
+}
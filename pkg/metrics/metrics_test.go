@@ -0,0 +1,45 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	ObserveDuration(DiscoveryDuration.WithLabelValues("artifacthub"), time.Now().Add(-time.Second))
+	ObserveDuration(GadgetInfoDuration.WithLabelValues("true"), time.Now())
+	SetBackgroundInstancesFunc(func() int { return 3 })
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatalf("reading metrics: %v", err)
+	}
+	for _, s := range []string{
+		`ig_mcp_discovery_duration_seconds_count{discoverer="artifacthub"} 1`,
+		`ig_mcp_gadget_info_duration_seconds_count{success="true"} 1`,
+		`ig_mcp_background_instances 3`,
+	} {
+		if !strings.Contains(string(body), s) {
+			t.Errorf("expected the metrics to contain %q, got:\n%s", s, body)
+		}
+	}
+}
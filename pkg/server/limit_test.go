@@ -0,0 +1,72 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimitRequestBody(t *testing.T) {
+	var got string
+	handler := limitRequestBody(8, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+	}))
+
+	for _, tc := range []struct {
+		name          string
+		body          string
+		contentLength int64
+		expected      int
+	}{
+		{name: "small", body: "12345678", contentLength: 8, expected: http.StatusOK},
+		{name: "large", body: "123456789", contentLength: 9, expected: http.StatusRequestEntityTooLarge},
+		{name: "large without content length", body: "123456789", contentLength: -1, expected: http.StatusRequestEntityTooLarge},
+	} {
+		got = ""
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(tc.body))
+		req.ContentLength = tc.contentLength
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tc.expected {
+			t.Errorf("%s: expected status %d, got %d", tc.name, tc.expected, rec.Code)
+		}
+		if tc.expected == http.StatusOK && got != tc.body {
+			t.Errorf("%s: expected handler to read %q, got %q", tc.name, tc.body, got)
+		}
+	}
+}
+
+func TestNewHTTPServer(t *testing.T) {
+	s := &Server{readTimeout: 10 * time.Second, idleTimeout: time.Minute}
+	srv := s.newHTTPServer("localhost:8080")
+	if srv.ReadTimeout != 10*time.Second || srv.IdleTimeout != time.Minute || srv.WriteTimeout != 0 {
+		t.Errorf("unexpected timeouts: read %s, idle %s, write %s", srv.ReadTimeout, srv.IdleTimeout, srv.WriteTimeout)
+	}
+
+	h := http.NotFoundHandler()
+	s.maxRequestBytes = 10
+	rec := httptest.NewRecorder()
+	s.limit(h).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", 11))))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected %d with a body limit, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+}
@@ -17,11 +17,14 @@ package server
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"net"
+	"net/http"
+	"time"
 
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/logging"
 	"github.com/inspektor-gadget/ig-mcp-server/pkg/tools"
 )
 
@@ -31,7 +34,10 @@ const (
 	StreamableHTTPTransport = "streamable-http"
 )
 
-var log = slog.Default().With("component", "sever")
+// streamableHTTPPath is the endpoint of the streamable-HTTP transport
+const streamableHTTPPath = "/mcp"
+
+var log = logging.Default().With("component", "sever")
 
 var SupportedTransports = []string{StdioTransport, SSETransport, StreamableHTTPTransport}
 
@@ -40,24 +46,91 @@ type Server struct {
 	mcpServer  *server.MCPServer
 	sseSever   *server.SSEServer
 	httpServer *server.StreamableHTTPServer
+	registry   *tools.GadgetToolRegistry
+
+	sseKeepAlive    time.Duration
+	maxRequestBytes int64
+	readTimeout     time.Duration
+	idleTimeout     time.Duration
+}
+
+// Option configures the Server.
+type Option func(*Server)
+
+// WithSSEKeepAlive sets the interval for keep-alive messages sent on SSE connections. Zero disables them.
+func WithSSEKeepAlive(interval time.Duration) Option {
+	return func(s *Server) {
+		s.sseKeepAlive = interval
+	}
+}
+
+// WithMaxRequestBytes sets the maximum size of request bodies for the SSE and streamable-HTTP transports, larger
+// requests are rejected with 413 Request Entity Too Large. Zero disables the limit.
+func WithMaxRequestBytes(maxBytes int64) Option {
+	return func(s *Server) {
+		s.maxRequestBytes = maxBytes
+	}
+}
+
+// WithReadTimeout sets the maximum duration for reading a request, including its body, for the SSE and
+// streamable-HTTP transports. It also caps how long their event streams stay open, so that the streams of clients
+// that went away are closed. Zero means no timeout.
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.readTimeout = timeout
+	}
+}
+
+// WithIdleTimeout sets how long idle keep-alive connections of the SSE and streamable-HTTP transports are kept open
+// waiting for the next request. Connections with an open event stream aren't idle, see WithReadTimeout to close them.
+// Zero means no timeout.
+func WithIdleTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.idleTimeout = timeout
+	}
 }
 
 // New creates a new instance of the Inspektor Gadget MCP server.
-func New(version string, registry *tools.GadgetToolRegistry) *Server {
+func New(version string, registry *tools.GadgetToolRegistry, opts ...Option) *Server {
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		registry.ForgetSession(session.SessionID())
+	})
 	ms := server.NewMCPServer(
 		"ig-mcp-mcpServer",
 		version,
 		server.WithLogging(),
 		server.WithRecovery(),
+		server.WithToolHandlerMiddleware(requestIDMiddleware),
+		server.WithHooks(hooks),
 	)
 
 	// Register callback to register tools
 	registry.RegisterCallback(func(tools ...server.ServerTool) {
 		ms.SetTools(tools...)
 	})
+	ms.AddResourceTemplate(registry.ResultsResourceTemplate())
 
-	return &Server{
+	s := &Server{
 		mcpServer: ms,
+		registry:  registry,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// requestIDMiddleware attaches a new request ID to the context of each tool call, so that the logs of the call are
+// correlated across the registry and gadget manager, see logging.WithRequestID.
+func requestIDMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+		start := time.Now()
+		log.DebugContext(ctx, "Calling tool", "tool", request.Params.Name)
+		res, err := next(ctx, request)
+		log.DebugContext(ctx, "Tool call done", "tool", request.Params.Name, "duration", time.Since(start), "error", err)
+		return res, err
 	}
 }
 
@@ -69,18 +142,58 @@ func (s *Server) Start(transport, host, port string) error {
 		return server.ServeStdio(s.mcpServer)
 	case SSETransport:
 		log.Info("Starting MCP server", "transport", transport, "host", host, "port", port)
-		s.sseSever = server.NewSSEServer(s.mcpServer)
-		return s.sseSever.Start(net.JoinHostPort(host, port))
+		var opts []server.SSEOption
+		if s.sseKeepAlive > 0 {
+			opts = append(opts, server.WithKeepAliveInterval(s.sseKeepAlive))
+		}
+		addr := net.JoinHostPort(host, port)
+		srv := s.newHTTPServer(addr)
+		opts = append(opts, server.WithHTTPServer(srv))
+		s.sseSever = server.NewSSEServer(s.mcpServer, opts...)
+		// The SSE server is its own handler, so it's set once created
+		srv.Handler = s.limit(s.sseSever)
+		return s.sseSever.Start(addr)
 	case StreamableHTTPTransport:
 		log.Info("Starting MCP server", "transport", transport, "host", host, "port", port)
-		s.httpServer = server.NewStreamableHTTPServer(s.mcpServer)
-		return s.httpServer.Start(net.JoinHostPort(host, port))
+		addr := net.JoinHostPort(host, port)
+		srv := s.newHTTPServer(addr)
+		s.httpServer = server.NewStreamableHTTPServer(s.mcpServer,
+			server.WithEndpointPath(streamableHTTPPath),
+			server.WithStreamableHTTPServer(srv),
+		)
+		mux := http.NewServeMux()
+		mux.Handle(streamableHTTPPath, s.limit(s.httpServer))
+		srv.Handler = mux
+		return s.httpServer.Start(addr)
 	}
 	return fmt.Errorf("unsupported transport: %s", transport)
 }
 
+// newHTTPServer creates the HTTP server of the SSE and streamable-HTTP transports. No write timeout is set, as it
+// would end the long-lived event streams.
+func (s *Server) newHTTPServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:        addr,
+		ReadTimeout: s.readTimeout,
+		IdleTimeout: s.idleTimeout,
+	}
+}
+
+// limit wraps h to enforce the request body limit and the lifetime of event streams, if any.
+func (s *Server) limit(h http.Handler) http.Handler {
+	if s.readTimeout > 0 {
+		h = limitStreamLifetime(s.readTimeout, h)
+	}
+	if s.maxRequestBytes > 0 {
+		h = limitRequestBody(s.maxRequestBytes, h)
+	}
+	return h
+}
+
 func (s *Server) Shutdown(ctx context.Context) error {
 	log.Info("Shutting down MCP server")
+	// Let connected clients know the tools are going away before closing the transports
+	s.registry.Clear()
 	if s.sseSever != nil {
 		if err := s.sseSever.Shutdown(ctx); err != nil {
 			return fmt.Errorf("shutting down SSE server: %w", err)
@@ -0,0 +1,105 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+// missingValue groups the events without the aggregated field
+const missingValue = "<none>"
+
+type aggregateGroup struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// validateAggregate checks that the aggregation field exists in the gadget.
+func validateAggregate(info *api.GadgetInfo, field string) error {
+	names := fieldNames(info)
+	if !slices.Contains(names, field) {
+		return fmt.Errorf("unknown aggregate field %q, valid fields are: %s", field, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// aggregateResults replaces the events of a result by one line per distinct value of field, with the number of events
+// having it, most frequent first. The number of events of the result is kept.
+func aggregateResults(result *gadgetmanager.RunResult, field string) (*gadgetmanager.RunResult, error) {
+	counts := make(map[string]int)
+	for _, line := range gadgetmanager.EventLines(result.Output) {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return nil, fmt.Errorf("decoding event: %w", err)
+		}
+		counts[eventValue(event, field)]++
+	}
+
+	groups := make([]aggregateGroup, 0, len(counts))
+	for value, count := range counts {
+		groups = append(groups, aggregateGroup{Value: value, Count: count})
+	}
+	slices.SortFunc(groups, func(a, b aggregateGroup) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+
+	var out strings.Builder
+	for _, g := range groups {
+		line, err := json.Marshal(g)
+		if err != nil {
+			return nil, fmt.Errorf("encoding aggregate group: %w", err)
+		}
+		out.Write(line)
+		out.WriteByte('\n')
+	}
+	aggregated := *result
+	aggregated.Output = out.String()
+	return &aggregated, nil
+}
+
+// eventValue returns the value of a field, given by its full name e.g. k8s.podName, as a string.
+func eventValue(event map[string]any, field string) string {
+	var value any = event
+	for _, part := range strings.Split(field, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return missingValue
+		}
+		if value, ok = m[part]; !ok {
+			return missingValue
+		}
+	}
+	switch v := value.(type) {
+	case nil:
+		return missingValue
+	case string:
+		return v
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
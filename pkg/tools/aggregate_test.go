@@ -0,0 +1,61 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHandlerAggregate(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	mgr.Result.Output = "{\"name\":\"a.com.\",\"qr\":\"Q\"}\n{\"name\":\"b.com.\",\"qr\":\"Q\"}\n{\"name\":\"a.com.\",\"qr\":\"R\"}\n"
+	mgr.Result.Events = 3
+
+	res, err := callTool(t, r, map[string]any{"aggregate": "name"})
+	if err != nil {
+		t.Fatalf("calling tool: %v", err)
+	}
+	text := resultText(t, res)
+	expected := "{\"value\":\"a.com.\",\"count\":2}\n{\"value\":\"b.com.\",\"count\":1}\n"
+	if !strings.Contains(text, expected) {
+		t.Errorf("expected aggregated results %q, got: %s", expected, text)
+	}
+
+	res, err = callTool(t, r, map[string]any{"aggregate": "unknown"})
+	if err != nil {
+		t.Fatalf("calling tool: %v", err)
+	}
+	if !res.IsError || !strings.Contains(resultText(t, res), "unknown aggregate field") {
+		t.Errorf("expected an unknown field error, got %v", res)
+	}
+}
+
+func TestEventValue(t *testing.T) {
+	event := map[string]any{
+		"k8s":  map[string]any{"podName": "nginx"},
+		"port": float64(53),
+	}
+	for field, expected := range map[string]string{
+		"k8s.podName": "nginx",
+		"port":        "53",
+		"k8s.node":    missingValue,
+		"port.number": missingValue,
+	} {
+		if got := eventValue(event, field); got != expected {
+			t.Errorf("expected %q for %s, got %q", expected, field, got)
+		}
+	}
+}
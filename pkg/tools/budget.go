@@ -0,0 +1,110 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+// reducedResultLen is the result size used once a session exceeded its result budget
+const reducedResultLen = 8 * 1024 // 8kb
+
+const budgetExceededNotice = "The results returned in this session exceeded the configured budget, so they are " +
+	"truncated more aggressively. Consider summarizing the previous results before running more gadgets.\n"
+
+// resultBudget keeps track of the bytes returned to each session.
+type resultBudget struct {
+	mu   sync.Mutex
+	used map[string]int
+}
+
+func (b *resultBudget) add(session string, n int) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used == nil {
+		b.used = make(map[string]int)
+	}
+	b.used[session] += n
+	return b.used[session]
+}
+
+func (b *resultBudget) get(session string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used[session]
+}
+
+func (b *resultBudget) remove(session string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.used, session)
+}
+
+// ForgetSession drops the state kept for a session once it ended, e.g. the results bytes returned to it, so that it
+// doesn't pile up on long-running servers.
+func (r *GadgetToolRegistry) ForgetSession(session string) {
+	r.resultBudget.remove(session)
+}
+
+// WithResultBudget sets the number of result bytes a session can receive before results are truncated to a smaller
+// size. Zero, the default, disables the budget.
+func WithResultBudget(bytes int) Option {
+	return func(r *GadgetToolRegistry) {
+		r.resultBudgetBytes = bytes
+	}
+}
+
+// formatResults truncates the results and accounts them to the session of the request.
+func (r *GadgetToolRegistry) formatResults(ctx context.Context, result *gadgetmanager.RunResult) string {
+	maxLen, exceeded := r.sessionResultLen(ctx)
+	out := truncateResults(result, maxLen)
+	r.accountResults(ctx, len(out))
+	if exceeded {
+		return budgetExceededNotice + out
+	}
+	return out
+}
+
+// sessionResultLen returns the maximum length of the results returned to the session of ctx, which is reduced once
+// the session exceeded its result budget, along with whether it did.
+func (r *GadgetToolRegistry) sessionResultLen(ctx context.Context) (int, bool) {
+	resultLen := r.resultLen()
+	if r.resultBudgetBytes <= 0 {
+		return resultLen, false
+	}
+	if r.resultBudget.get(sessionID(ctx)) < r.resultBudgetBytes {
+		return resultLen, false
+	}
+	return min(resultLen, reducedResultLen), true
+}
+
+// accountResults adds n bytes of results returned to the session of ctx to its budget.
+func (r *GadgetToolRegistry) accountResults(ctx context.Context, n int) {
+	if r.resultBudgetBytes > 0 {
+		r.resultBudget.add(sessionID(ctx), n)
+	}
+}
+
+func sessionID(ctx context.Context) string {
+	if s := server.ClientSessionFromContext(ctx); s != nil {
+		return s.SessionID()
+	}
+	return ""
+}
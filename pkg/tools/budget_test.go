@@ -0,0 +1,33 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"testing"
+)
+
+func TestForgetSession(t *testing.T) {
+	r, _ := newTestRegistry(t, WithResultBudget(100))
+	r.resultBudget.add("session-1", 10)
+	r.resultBudget.add("session-2", 20)
+
+	r.ForgetSession("session-1")
+	if _, ok := r.resultBudget.used["session-1"]; ok {
+		t.Error("expected the budget of the ended session to be removed")
+	}
+	if used := r.resultBudget.get("session-2"); used != 20 {
+		t.Errorf("expected the budget of other sessions to be kept, got %d", used)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func (r *GadgetToolRegistry) newToolCatalogTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Returns all the tools currently registered with their name, description, input schema and " +
+			"annotations as JSON, e.g. to document or snapshot the capabilities of this server."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"tool-catalog",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.toolCatalogHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) toolCatalogHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		out, err := r.toolCatalog()
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
+
+// toolCatalog returns the registered tools sorted by name, marshalled as in a tools/list response.
+func (r *GadgetToolRegistry) toolCatalog() ([]byte, error) {
+	r.mu.Lock()
+	all := r.all()
+	r.mu.Unlock()
+
+	tools := make([]mcp.Tool, 0, len(all))
+	for _, t := range all {
+		tools = append(tools, t.Tool)
+	}
+	slices.SortFunc(tools, func(a, b mcp.Tool) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	out, err := json.MarshalIndent(tools, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshalling tool catalog: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestToolCatalog(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	r.addCustomTool(newWaitTool())
+	r.addCustomTool(r.newVersionTool())
+
+	out, err := r.toolCatalog()
+	if err != nil {
+		t.Fatalf("getting tool catalog: %v", err)
+	}
+	var catalog []struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		InputSchema map[string]any `json:"inputSchema"`
+	}
+	if err := json.Unmarshal(out, &catalog); err != nil {
+		t.Fatalf("decoding tool catalog: %v", err)
+	}
+	var names []string
+	for _, tool := range catalog {
+		names = append(names, tool.Name)
+		if tool.Description == "" || tool.InputSchema == nil {
+			t.Errorf("expected tool %q to have a description and an input schema", tool.Name)
+		}
+	}
+	expected := []string{"gadget-version", "wait"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("expected tools %v, got %v", expected, names)
+	}
+}
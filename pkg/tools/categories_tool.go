@@ -0,0 +1,103 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// genericCategories are keywords shared by all gadgets, which don't help to pick one
+var genericCategories = []string{"gadget", "gadgets", "inspektor-gadget", "ebpf"}
+
+// SetGadgetCategories sets the categories of the gadget images, e.g. the keywords found by a discoverer, reported by
+// the gadget-categories tool. Gadgets without categories are grouped by the prefix of their tool name, e.g. 'trace'
+// for trace_dns.
+func (r *GadgetToolRegistry) SetGadgetCategories(categories map[string][]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.categories = make(map[string][]string, len(categories))
+	for image, c := range categories {
+		r.categories[NormalizeImageRef(image)] = c
+	}
+}
+
+func (r *GadgetToolRegistry) newGadgetCategoriesTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Lists the registered gadget tools grouped by category (e.g. trace, top, snapshot, profile, " +
+			"or topics like dns and network). Use it to find the right kind of gadget for a task."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"gadget-categories",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.gadgetCategoriesHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) gadgetCategoriesHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		groups := r.gadgetCategories()
+		if len(groups) == 0 {
+			return mcp.NewToolResultText("No gadget tools are registered"), nil
+		}
+		out, err := json.Marshal(groups)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling gadget categories: %w", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
+
+// gadgetCategories returns the names of the registered gadget tools by category, sorted.
+func (r *GadgetToolRegistry) gadgetCategories() map[string][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	groups := make(map[string][]string)
+	for _, image := range r.images {
+		tool, ok := r.tools[image]
+		if !ok {
+			continue
+		}
+		name := tool.Tool.Name
+		var categories []string
+		for _, c := range r.categories[image] {
+			c = strings.ToLower(strings.TrimSpace(c))
+			if c != "" && !slices.Contains(genericCategories, c) && !slices.Contains(categories, c) {
+				categories = append(categories, c)
+			}
+		}
+		if len(categories) == 0 {
+			prefix, _, _ := strings.Cut(name, "_")
+			categories = []string{prefix}
+		}
+		for _, c := range categories {
+			groups[c] = append(groups[c], name)
+		}
+	}
+	for _, names := range groups {
+		slices.Sort(names)
+	}
+	return groups
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestGadgetCategories(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	images := []string{"trace_dns:latest", "top_file:latest", "snapshot_process:latest"}
+	r.SetGadgetCategories(map[string][]string{
+		"trace_dns:latest": {"DNS", "network", "gadget"},
+	})
+	r.images = images
+	for _, img := range images {
+		name := strings.TrimSuffix(img, ":latest")
+		r.tools[img] = server.ServerTool{Tool: mcp.NewTool(name)}
+	}
+
+	expected := map[string][]string{
+		"dns":      {"trace_dns"},
+		"network":  {"trace_dns"},
+		"top":      {"top_file"},
+		"snapshot": {"snapshot_process"},
+	}
+	groups := r.gadgetCategories()
+	if len(groups) != len(expected) {
+		t.Fatalf("expected categories %v, got %v", expected, groups)
+	}
+	for c, names := range expected {
+		if !slices.Equal(groups[c], names) {
+			t.Errorf("expected category %q to have %v, got %v", c, names, groups[c])
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
+)
+
+const (
+	// readyTimeout is how long to wait for a freshly deployed Inspektor Gadget to serve gadgets
+	readyTimeout  = time.Minute
+	readyInterval = 2 * time.Second
+)
+
+func (r *GadgetToolRegistry) newDeployAndRunTool(images []string) server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Deploys Inspektor Gadget if it isn't deployed yet, waits for it to be ready and runs a gadget " +
+			"in the foreground, returning its results. Use it instead of deploying and running a gadget in separate steps."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithString("image",
+			mcp.Required(),
+			mcp.Description("Gadget image to run e.g. trace_dns:latest"),
+		),
+		mcp.WithObject("params",
+			mcp.Description("key-value pairs of parameters to pass to the gadget, see gadget-help for the available ones"),
+		),
+		mcp.WithNumber("timeout",
+			mcp.Description(fmt.Sprintf("Timeout in seconds for the gadget to run (default %d)", int(r.defaultTimeout.Seconds()))),
+		),
+		mcp.WithString("chart_version",
+			mcp.Description("Version of the Inspektor Gadget Helm chart to deploy, only set if user explicitly specifies a version"),
+		),
+	}
+	tool := mcp.NewTool(
+		"deploy-and-run",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.deployAndRunHandler(images),
+	}
+}
+
+func (r *GadgetToolRegistry) deployAndRunHandler(images []string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return r.deployAndRun(ctx, request, images, r.ensureDeployed)
+	}
+}
+
+// ensureDeployedFunc deploys Inspektor Gadget if it isn't deployed yet, reporting whether it did.
+type ensureDeployedFunc func(ctx context.Context, request mcp.CallToolRequest) (bool, error)
+
+// deployAndRun implements deploy-and-run, ensureDeployed is called to deploy Inspektor Gadget if needed.
+func (r *GadgetToolRegistry) deployAndRun(ctx context.Context, request mcp.CallToolRequest, images []string,
+	ensureDeployed ensureDeployedFunc) (*mcp.CallToolResult, error) {
+	if env := r.environment(); env != deployer.KubernetesEnv {
+		return mcp.NewToolResultError(fmt.Sprintf("deploy-and-run is only supported in the Kubernetes environment, not in the %s one", env)), nil
+	}
+	image := request.GetString("image", "")
+	if image == "" {
+		return nil, fmt.Errorf("an image is required")
+	}
+
+	deployed, err := ensureDeployed(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var info *api.GadgetInfo
+	if deployed {
+		// The gadget pods may still be starting, wait for them to serve the gadget
+		info, err = r.waitForGadget(ctx, image)
+	} else {
+		info, err = r.gadgetMgr.GetInfo(ctx, image)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if deployed && !r.skipRegisterAfterDeploy {
+		if err := r.registerAndNotify(ctx, images); err != nil {
+			log.Warn("failed to register tool", "error", err)
+		}
+	}
+
+	// Run in the foreground, the combined result is meant to be returned in a single step
+	args := maps.Clone(request.GetArguments())
+	delete(args, "background")
+	runRequest := request
+	runRequest.Params.Arguments = args
+	res, err := r.handlerFromGadgetInfo(info)(ctx, runRequest)
+	if err != nil || !deployed {
+		return res, err
+	}
+	msg := fmt.Sprintf("Inspektor Gadget has been deployed in namespace %s.", defaultNamespace)
+	res.Content = append([]mcp.Content{mcp.NewTextContent(msg)}, res.Content...)
+	return res, nil
+}
+
+// ensureDeployed deploys Inspektor Gadget with the chart version of the request if it isn't deployed yet.
+func (r *GadgetToolRegistry) ensureDeployed(ctx context.Context, request mcp.CallToolRequest) (bool, error) {
+	_, err := r.inspektorGadgetNamespace(ctx)
+	switch {
+	case err == nil:
+		return false, nil
+	case !errors.Is(err, ErrNotDeployed):
+		return false, err
+	}
+	chartUrl, err := r.resolveChartURL(request.GetString("chart_version", ""))
+	if err != nil {
+		return false, err
+	}
+	if err := r.deploy(ctx, chartUrl, defaultReleaseName, defaultNamespace, 0, newDeployProgress(ctx, request).report); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// waitForGadget waits until Inspektor Gadget can serve the given gadget, which is only the case once its pods are
+// running, and returns the gadget info.
+func (r *GadgetToolRegistry) waitForGadget(ctx context.Context, image string) (*api.GadgetInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(readyInterval)
+	defer ticker.Stop()
+	for {
+		info, err := r.gadgetMgr.GetInfo(ctx, image)
+		if err == nil {
+			return info, nil
+		}
+		log.DebugContext(ctx, "Waiting for Inspektor Gadget to be ready", "image", image, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for Inspektor Gadget to serve %s: %w", image, err)
+		case <-ticker.C:
+		}
+	}
+}
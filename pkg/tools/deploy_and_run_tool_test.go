@@ -0,0 +1,96 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestDeployAndRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		image      string
+		deployed   bool
+		wantErr    bool
+		wantDeploy bool
+	}{
+		{name: "already deployed", image: "trace_dns:latest"},
+		{name: "freshly deployed", image: "trace_dns:latest", deployed: true, wantDeploy: true},
+		{name: "bad image", image: "trace_dsn:latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, mgr := newTestRegistry(t)
+			ensureDeployed := func(context.Context, mcp.CallToolRequest) (bool, error) {
+				return tt.deployed, nil
+			}
+			args := map[string]any{"image": tt.image, "background": true}
+			var request mcp.CallToolRequest
+			request.Params.Arguments = args
+
+			start := time.Now()
+			res, err := r.deployAndRun(context.Background(), request, []string{"trace_dns:latest"}, ensureDeployed)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, ok := args["background"]; !ok {
+				t.Error("expected the request arguments to be left untouched")
+			}
+			if tt.wantErr {
+				if !res.IsError || len(mgr.RunCalls()) != 0 {
+					t.Errorf("expected an error without running the gadget, got %v", res)
+				}
+				if elapsed := time.Since(start); elapsed >= readyInterval {
+					t.Errorf("expected the error without waiting for Inspektor Gadget, took %s", elapsed)
+				}
+				return
+			}
+			if res.IsError || len(mgr.RunCalls()) != 1 || len(mgr.DetachedCalls()) != 0 {
+				t.Fatalf("expected a single foreground run, got %v", res)
+			}
+			text, ok := res.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("expected text content, got %T", res.Content[0])
+			}
+			if got := strings.Contains(text.Text, "has been deployed"); got != tt.wantDeploy {
+				t.Errorf("expected deploy message %v, got %q", tt.wantDeploy, text.Text)
+			}
+			if got := len(r.tools) > 0; got != tt.wantDeploy {
+				t.Errorf("expected gadget tools registered %v, got %d tools", tt.wantDeploy, len(r.tools))
+			}
+		})
+	}
+}
+
+func TestDeployAndRunLinux(t *testing.T) {
+	r, mgr := newTestRegistry(t, WithEnvironmentInfo(EnvironmentInfo{Runtime: gadgetmanager.RuntimeGrpcLinux}))
+	ensureDeployed := func(context.Context, mcp.CallToolRequest) (bool, error) {
+		t.Error("expected no deploy on Linux")
+		return false, nil
+	}
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"image": "trace_dns:latest"}
+	res, err := r.deployAndRun(context.Background(), request, nil, ensureDeployed)
+	if err != nil || !res.IsError || len(mgr.RunCalls()) != 0 {
+		t.Errorf("expected deploy-and-run to be rejected on Linux, got %v, %v", res, err)
+	}
+}
@@ -17,10 +17,13 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
 )
@@ -46,6 +49,15 @@ func newDeployTool(registry *GadgetToolRegistry, images []string) server.ServerT
 		mcp.WithString("chart_version",
 			mcp.Description("Version of the Inspektor Gadget Helm chart to deploy, only set if user explicitly specifies a version"),
 		),
+		mcp.WithNumber("min_ready_pods",
+			mcp.Description("Minimum number of gadget pods to wait for instead of one per node, e.g. when some nodes are cordoned. "+
+				"Only set if the deploy timed out waiting for pods or the user explicitly asks for it"),
+		),
+		mcp.WithBoolean("create_namespace",
+			mcp.Description("Whether to create the namespace, set it to false to deploy into an existing namespace e.g. one "+
+				"managed by policies. Only set if the user explicitly asks for it"),
+			mcp.DefaultBool(true),
+		),
 	}
 	tool := mcp.NewTool(
 		"deploy_inspektor_gadget",
@@ -60,31 +72,37 @@ func newDeployTool(registry *GadgetToolRegistry, images []string) server.ServerT
 
 func deployHandler(registry *GadgetToolRegistry, images []string) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		var err error
-		version := request.GetString("chart_version", "")
-		if version == "" {
-			version, err = getLatestChartVersion()
-			if err != nil {
-				return nil, fmt.Errorf("get latest chart version: %w", err)
-			}
+		if env := registry.environment(); env != deployer.KubernetesEnv {
+			return mcp.NewToolResultError(fmt.Sprintf("deploying Inspektor Gadget is only supported in the Kubernetes environment, not in the %s one", env)), nil
+		}
+		chartUrl, err := registry.resolveChartURL(request.GetString("chart_version", ""))
+		if err != nil {
+			return nil, err
 		}
-		chartUrl := fmt.Sprintf("%s:%s", defaultChartUrl, version)
 		releaseName := request.GetString("release", defaultReleaseName)
 		namespace := request.GetString("namespace", defaultNamespace)
-
-		ist, err := deployer.NewDeployer(deployer.KubernetesEnv)
-		if err != nil {
-			return nil, fmt.Errorf("create deployer: %w", err)
+		minReadyPods := request.GetInt("min_ready_pods", 0)
+		if minReadyPods < 0 {
+			return mcp.NewToolResultError("min_ready_pods must not be negative"), nil
 		}
 
-		opts := []deployer.RunOption{
-			deployer.WithChartURL(chartUrl),
-			deployer.WithReleaseName(releaseName),
-			deployer.WithNamespace(namespace),
+		createNamespace := request.GetBool("create_namespace", true)
+		if !createNamespace {
+			if err := checkNamespaceExists(ctx, namespace); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 		}
-		err = ist.Deploy(ctx, opts...)
+
+		progress := newDeployProgress(ctx, request)
+		err = registry.deploy(ctx, chartUrl, releaseName, namespace, minReadyPods, progress.report,
+			deployer.WithSkipNamespaceCreation(!createNamespace))
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return mcp.NewToolResultError(fmt.Sprintf("%s\n\nDeploy log:\n%s", err, progress)), nil
+		}
+
+		if registry.skipRegisterAfterDeploy {
+			msg := "Inspektor Gadget deploy completed successfully, call refresh-gadgets to register the gadget tools once it's ready"
+			return mcp.NewToolResultText(fmt.Sprintf("%s\n\nDeploy log:\n%s", msg, progress)), nil
 		}
 
 		// Register the tool with the registry
@@ -94,21 +112,117 @@ func deployHandler(registry *GadgetToolRegistry, images []string) server.ToolHan
 			log.Debug("Waiting for Inspektor Gadget to be fully deployed before registering tools")
 			time.Sleep(10 * time.Second)
 
-			registry.mu.Lock()
-			defer registry.mu.Unlock()
-			err = registry.registerGadgets(ctx, images)
-			if err != nil {
+			if err := registry.registerAndNotify(ctx, images); err != nil {
 				log.Warn("failed to register tool", "error", err)
-				return
-			}
-			for _, callback := range registry.callbacks {
-				log.Debug("Invoking tool registry callback", "tools_count", len(registry.tools))
-				callback(registry.all()...)
 			}
 		}()
 
-		return mcp.NewToolResultText("Inspektor Gadget deploy completed successfully"), nil
+		msg := "Inspektor Gadget deploy completed successfully"
+		if minReadyPods > 0 {
+			ready, total, err := deployer.ReadyPods(ctx, namespace, registry.podSelector)
+			if err != nil {
+				log.WarnContext(ctx, "Failed to count ready gadget pods", "error", err)
+			} else {
+				msg += fmt.Sprintf(", %d of %d gadget pods are ready", ready, total)
+			}
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("%s\n\nDeploy log:\n%s", msg, progress)), nil
+	}
+}
+
+// deploy deploys Inspektor Gadget with the given chart, waiting for its resources to be ready. If minReadyPods is
+// positive, it only waits for that many gadget pods to be ready. progress, if not nil, receives the deploy progress
+// messages. extraOpts are passed to the deployer along with the ones derived from the registry.
+func (r *GadgetToolRegistry) deploy(ctx context.Context, chartUrl, releaseName, namespace string, minReadyPods int,
+	progress func(msg string), extraOpts ...deployer.RunOption) error {
+	deployerOpts := []deployer.Option{deployer.WithUserAgent(r.userAgent)}
+	if r.deployRetries != nil {
+		deployerOpts = append(deployerOpts, deployer.WithRetries(*r.deployRetries))
+	}
+	ist, err := deployer.NewDeployer(deployer.KubernetesEnv, deployerOpts...)
+	if err != nil {
+		return fmt.Errorf("create deployer: %w", err)
+	}
+
+	opts := []deployer.RunOption{
+		deployer.WithChartURL(chartUrl),
+		deployer.WithReleaseName(releaseName),
+		deployer.WithNamespace(namespace),
+	}
+	if r.verifyGadgets != nil {
+		opts = append(opts, deployer.WithVerifyGadgets(*r.verifyGadgets))
+	}
+	if minReadyPods > 0 {
+		opts = append(opts, deployer.WithMinReadyPods(minReadyPods, r.podSelector))
+	}
+	if progress != nil {
+		opts = append(opts, deployer.WithProgress(progress))
+	}
+	opts = append(opts, extraOpts...)
+	return ist.Deploy(ctx, opts...)
+}
+
+// checkNamespaceExists returns an error if the namespace Inspektor Gadget is deployed into without creating it
+// doesn't exist, as the deploy would otherwise fail with a less clear error.
+func checkNamespaceExists(ctx context.Context, namespace string) error {
+	client, err := newKubernetesClient()
+	if err != nil {
+		return err
+	}
+	_, err = client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return fmt.Errorf("namespace %s doesn't exist, create it first or set create_namespace to true", namespace)
+	case err != nil:
+		return fmt.Errorf("getting namespace %s: %w", namespace, err)
+	}
+	return nil
+}
+
+// registerAndNotify registers the gadget tools for the given images and invokes the registry callbacks.
+func (r *GadgetToolRegistry) registerAndNotify(ctx context.Context, images []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.registerGadgets(ctx, images); err != nil {
+		return err
+	}
+	for _, callback := range r.callbacks {
+		log.Debug("Invoking tool registry callback", "tools_count", len(r.tools))
+		callback(r.all()...)
+	}
+	return nil
+}
+
+// resolveChartURL returns the URL of the chart to deploy for version, the latest one if empty. A chart URL set with
+// WithChartURL that is pinned to a version is used as is.
+func (r *GadgetToolRegistry) resolveChartURL(version string) (string, error) {
+	if isPinnedChartURL(r.chartURL) {
+		if version != "" {
+			return "", fmt.Errorf("the chart URL %s is pinned to a version, chart_version can't be used", r.chartURL)
+		}
+		return r.chartURL, nil
+	}
+	if version == "" {
+		var err error
+		version, err = getLatestChartVersion()
+		if err != nil {
+			return "", fmt.Errorf("get latest chart version: %w", err)
+		}
+	}
+	return fmt.Sprintf("%s:%s", r.chartURL, version), nil
+}
+
+// isPinnedChartURL reports whether url refers to a specific chart version: an OCI reference with a tag or digest, or
+// a chart archive.
+func isPinnedChartURL(url string) bool {
+	if strings.HasSuffix(url, ".tgz") {
+		return true
+	}
+	name := strings.TrimPrefix(url, "oci://")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
 	}
+	return strings.ContainsAny(name, ":@")
 }
 
 // getLatestChartVersion is a placeholder function that simulates fetching the latest chart version.
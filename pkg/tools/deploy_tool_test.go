@@ -0,0 +1,97 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestResolveChartURL(t *testing.T) {
+	const mirror = "oci://mirror.example.com:5000/charts/gadget"
+	const pinned = "oci://mirror.example.com/charts/gadget:0.40.0"
+	tests := []struct {
+		name     string
+		chartURL string
+		version  string
+		expected string
+		// latest only checks that a version is appended, the latest one isn't known in advance
+		latest  bool
+		wantErr bool
+	}{
+		{name: "default", version: "0.41.0", expected: defaultChartUrl + ":0.41.0"},
+		{name: "default latest", latest: true, expected: defaultChartUrl + ":"},
+		{name: "mirror", chartURL: mirror, version: "0.41.0", expected: mirror + ":0.41.0"},
+		{name: "pinned", chartURL: pinned, expected: pinned},
+		{name: "pinned with version", chartURL: pinned, version: "0.41.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.chartURL != "" {
+				opts = append(opts, WithChartURL(tt.chartURL))
+			}
+			r, _ := newTestRegistry(t, opts...)
+			url, err := r.resolveChartURL(tt.version)
+			switch {
+			case tt.wantErr:
+				if err == nil {
+					t.Errorf("expected an error, got %q", url)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.latest && (!strings.HasPrefix(url, tt.expected) || url == tt.expected):
+				t.Errorf("expected the latest chart version after %q, got %q", tt.expected, url)
+			case !tt.latest && url != tt.expected:
+				t.Errorf("expected %q, got %q", tt.expected, url)
+			}
+		})
+	}
+}
+
+func TestDeployToolCreateNamespace(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	prop, ok := newDeployTool(r, nil).Tool.InputSchema.Properties["create_namespace"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected a create_namespace argument")
+	}
+	if prop["type"] != "boolean" || prop["default"] != true {
+		t.Errorf("expected a boolean defaulting to true, got %v", prop)
+	}
+}
+
+func TestDeployToolsLinux(t *testing.T) {
+	r, _ := newTestRegistry(t, WithEnvironmentInfo(EnvironmentInfo{Runtime: gadgetmanager.RuntimeGrpcLinux}))
+	handlers := map[string]server.ToolHandlerFunc{
+		"deploy":          deployHandler(r, nil),
+		"undeploy":        undeployHandler(r),
+		"deployment plan": r.deploymentPlanHandler(),
+		"is deployed":     r.isDeployedHandler,
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			res, err := handler(context.Background(), mcp.CallToolRequest{})
+			if err != nil || !res.IsError || !strings.Contains(resultText(t, res), "Kubernetes environment") {
+				t.Errorf("expected the tool to be rejected on Linux, got %v, %v", res, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
+)
+
+const (
+	planActionInstall = "install"
+	planActionNone    = "none"
+)
+
+// deploymentPlan describes what deploy_inspektor_gadget would do with the same arguments.
+type deploymentPlan struct {
+	// Action is planActionInstall, or planActionNone if Inspektor Gadget is already deployed
+	Action        string `json:"action"`
+	ChartURL      string `json:"chartUrl"`
+	ReleaseName   string `json:"releaseName"`
+	Namespace     string `json:"namespace"`
+	VerifyGadgets *bool  `json:"verifyGadgets,omitempty"`
+	MinReadyPods  int    `json:"minReadyPods,omitempty"`
+	// DeployedNamespace is where Inspektor Gadget is already deployed, by any means
+	DeployedNamespace string `json:"deployedNamespace,omitempty"`
+	// ManagedRelease is true if the release exists and was deployed by this server
+	ManagedRelease bool `json:"managedRelease"`
+	// ReleaseError is set if the release couldn't be looked up, e.g. because it doesn't exist
+	ReleaseError string `json:"releaseError,omitempty"`
+}
+
+func (r *GadgetToolRegistry) newDeploymentPlanTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Reports what deploy_inspektor_gadget would do with the same arguments, without changing " +
+			"the cluster: the chart URL and version, the release name and namespace, and whether Inspektor Gadget or " +
+			"the release already exist. Use it to ask the user for confirmation before deploying."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("namespace",
+			mcp.Description("Kubernetes namespace to deploy Inspektor Gadget into, only set if user explicitly specifies a namespace"),
+			mcp.DefaultString(defaultNamespace),
+		),
+		mcp.WithString("release",
+			mcp.Description("Name of Helm release to create for Inspektor Gadget, only set if user explicitly specifies a release name"),
+			mcp.DefaultString(defaultReleaseName),
+		),
+		mcp.WithString("chart_version",
+			mcp.Description("Version of the Inspektor Gadget Helm chart to deploy, only set if user explicitly specifies a version"),
+		),
+		mcp.WithNumber("min_ready_pods",
+			mcp.Description("Minimum number of gadget pods to wait for instead of one per node"),
+		),
+	}
+	tool := mcp.NewTool(
+		"deployment-plan",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.deploymentPlanHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) deploymentPlanHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if env := r.environment(); env != deployer.KubernetesEnv {
+			return mcp.NewToolResultError(fmt.Sprintf("deployment plans are only supported in the Kubernetes environment, not in the %s one", env)), nil
+		}
+		chartUrl, err := r.resolveChartURL(request.GetString("chart_version", ""))
+		if err != nil {
+			return nil, err
+		}
+		plan := deploymentPlan{
+			Action:        planActionInstall,
+			ChartURL:      chartUrl,
+			ReleaseName:   request.GetString("release", defaultReleaseName),
+			Namespace:     request.GetString("namespace", defaultNamespace),
+			VerifyGadgets: r.verifyGadgets,
+			MinReadyPods:  request.GetInt("min_ready_pods", 0),
+		}
+
+		ns, err := r.inspektorGadgetNamespace(ctx)
+		switch {
+		case err == nil:
+			plan.Action = planActionNone
+			plan.DeployedNamespace = ns
+		case !errors.Is(err, ErrNotDeployed):
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		ist, err := deployer.NewDeployer(deployer.KubernetesEnv, deployer.WithUserAgent(r.userAgent))
+		if err != nil {
+			return nil, fmt.Errorf("create deployer: %w", err)
+		}
+		plan.ManagedRelease, err = ist.IsDeployed(ctx,
+			deployer.WithReleaseName(plan.ReleaseName),
+			deployer.WithNamespace(plan.Namespace),
+		)
+		if err != nil {
+			plan.ReleaseError = err.Error()
+		}
+
+		out, err := json.Marshal(plan)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling deployment plan: %w", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
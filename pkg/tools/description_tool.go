@@ -0,0 +1,60 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func (r *GadgetToolRegistry) newDescriptionTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Returns the description of the tool of a gadget exactly as rendered from the description " +
+			"template, i.e. what the model sees. Use it to debug or tune custom description templates."),
+		mcp.WithString("image",
+			mcp.Required(),
+			mcp.Description("Gadget image e.g. trace_dns:latest"),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"gadget-description",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.descriptionHandler,
+	}
+}
+
+func (r *GadgetToolRegistry) descriptionHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	image := request.GetString("image", "")
+	if image == "" {
+		return nil, fmt.Errorf("an image is required")
+	}
+
+	info, err := r.gadgetMgr.GetInfo(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("getting gadget info for %s: %w", image, err)
+	}
+	tool, err := r.toolFromGadgetInfo(info)
+	if err != nil {
+		return nil, fmt.Errorf("rendering the description of %s: %w", image, err)
+	}
+	return mcp.NewToolResultText(tool.Description), nil
+}
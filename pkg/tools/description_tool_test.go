@@ -0,0 +1,39 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"testing"
+	"text/template"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestGadgetDescription(t *testing.T) {
+	tmpl := template.Must(template.New(descriptionTemplateName).Parse("Custom description of {{.Name}}: {{.Description}}"))
+	r, _ := newTestRegistry(t, WithDescriptionTemplates(tmpl))
+
+	res, err := r.descriptionHandler(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]any{"image": "trace_dns:latest"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "Custom description of trace_dns: trace DNS requests and responses"
+	if text := res.Content[0].(mcp.TextContent).Text; text != expected {
+		t.Errorf("expected description %q, got %q", expected, text)
+	}
+}
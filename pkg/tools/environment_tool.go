@@ -0,0 +1,115 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+// EnvironmentInfo describes how the server is set up, it's reported by the environment-info tool.
+type EnvironmentInfo struct {
+	Runtime   string `json:"runtime"`
+	Transport string `json:"transport"`
+	// Discoverer is the gadget discoverer in use, empty if the gadget images are configured explicitly
+	Discoverer string `json:"discoverer,omitempty"`
+}
+
+type environmentInfo struct {
+	EnvironmentInfo
+	Environment string `json:"environment"`
+	Deployed    bool   `json:"deployed"`
+	Namespace   string `json:"namespace,omitempty"`
+	Error       string `json:"error,omitempty"`
+	// SelfCheck is the result of the startup self-check, see SelfCheck
+	SelfCheck *selfCheckResult `json:"selfCheck,omitempty"`
+}
+
+// WithEnvironmentInfo sets the server setup reported by the environment-info tool.
+func WithEnvironmentInfo(info EnvironmentInfo) Option {
+	return func(r *GadgetToolRegistry) {
+		r.envInfo = info
+	}
+}
+
+func (r *GadgetToolRegistry) newEnvironmentInfoTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Describes the environment this server works with: the environment and runtime type, the MCP " +
+			"transport, the gadget discoverer, whether Inspektor Gadget is deployed and in which namespace and the result of the " +
+			"startup self-check. Use it to diagnose why gadgets can't be run."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"environment-info",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.environmentInfoHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) environmentInfoHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		info := environmentInfo{
+			EnvironmentInfo: r.envInfo,
+			Environment:     r.environment(),
+			SelfCheck:       r.lastSelfCheck(),
+		}
+		// On Linux, the daemon isn't deployed by this server, it's only known to be running once a gadget is run
+		if info.Environment == deployer.KubernetesEnv {
+			ns, err := r.inspektorGadgetNamespace(ctx)
+			switch {
+			case err == nil:
+				info.Deployed = true
+				info.Namespace = ns
+			case !errors.Is(err, ErrNotDeployed):
+				info.Error = err.Error()
+			}
+		}
+		out, err := json.Marshal(info)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling environment info: %w", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
+
+// environment returns the environment of the configured runtime, see deployer.KubernetesEnv and deployer.LinuxEnv.
+func (r *GadgetToolRegistry) environment() string {
+	if r.envInfo.Runtime == gadgetmanager.RuntimeGrpcLinux {
+		return deployer.LinuxEnv
+	}
+	return deployer.KubernetesEnv
+}
+
+// environmentTitle returns how env is named in the tool descriptions e.g. "Kubernetes" for deployer.KubernetesEnv.
+func environmentTitle(env string) string {
+	switch env {
+	case deployer.KubernetesEnv:
+		return "Kubernetes"
+	case deployer.LinuxEnv:
+		return "Linux"
+	}
+	return env
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+// backendCheckTimeout bounds the time to check that the backend of the current environment is reachable
+const backendCheckTimeout = 5 * time.Second
+
+// environmentStatus is reported by the environments tool for each supported environment.
+type environmentStatus struct {
+	Name    string `json:"name"`
+	Runtime string `json:"runtime"`
+	Current bool   `json:"current"`
+	// Functional is only known for the current environment
+	Functional *bool  `json:"functional,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (r *GadgetToolRegistry) newEnvironmentsTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Lists the environments this server supports (kubernetes, linux), which one is in use and " +
+			"whether it is functional: the cluster or ig daemon is reachable and Inspektor Gadget is deployed. Use it " +
+			"when gadgets fail to run to get an actionable error."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"environments",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.environmentsHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) environmentsHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		envs := []environmentStatus{
+			{Name: deployer.KubernetesEnv, Runtime: gadgetmanager.RuntimeGrpcK8s},
+			{Name: deployer.LinuxEnv, Runtime: gadgetmanager.RuntimeGrpcLinux},
+		}
+		current := r.environment()
+		for i := range envs {
+			if envs[i].Name != current {
+				continue
+			}
+			envs[i].Current = true
+			err := r.checkEnvironment(ctx)
+			functional := err == nil
+			envs[i].Functional = &functional
+			if err != nil {
+				envs[i].Error = err.Error()
+			}
+		}
+		out, err := json.Marshal(envs)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling environments: %w", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
+
+// checkEnvironment checks that gadgets can be run in the current environment, returning an error describing what to
+// fix otherwise.
+func (r *GadgetToolRegistry) checkEnvironment(ctx context.Context) error {
+	if r.environment() == deployer.KubernetesEnv {
+		_, err := r.inspektorGadgetNamespace(ctx)
+		switch {
+		case errors.Is(err, ErrNotDeployed):
+			return errors.New("Inspektor Gadget is not deployed, use deploy_inspektor_gadget to deploy it")
+		case err != nil:
+			return fmt.Errorf("cluster not reachable, check the kubeconfig: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backendCheckTimeout)
+	defer cancel()
+	if _, err := r.gadgetMgr.ListInstances(ctx); err != nil {
+		if r.environment() == deployer.LinuxEnv {
+			return fmt.Errorf("ig daemon not reachable, check that it is running and the -linux-socket flag: %w", err)
+		}
+		return fmt.Errorf("Inspektor Gadget not reachable: %w", err)
+	}
+	return nil
+}
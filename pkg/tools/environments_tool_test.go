@@ -0,0 +1,58 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestEnvironments(t *testing.T) {
+	r, mgr := newTestRegistry(t, WithEnvironmentInfo(EnvironmentInfo{Runtime: gadgetmanager.RuntimeGrpcLinux}))
+	var request mcp.CallToolRequest
+	call := func() []environmentStatus {
+		t.Helper()
+		res, err := r.environmentsHandler()(context.Background(), request)
+		if err != nil {
+			t.Fatalf("calling environments: %v", err)
+		}
+		var envs []environmentStatus
+		if err := json.Unmarshal([]byte(resultText(t, res)), &envs); err != nil {
+			t.Fatalf("decoding environments: %v", err)
+		}
+		return envs
+	}
+
+	envs := call()
+	if len(envs) != 2 || envs[0].Current || envs[0].Functional != nil || !envs[1].Current {
+		t.Fatalf("expected linux to be the only current environment, got %+v", envs)
+	}
+	if envs[1].Functional == nil || !*envs[1].Functional {
+		t.Errorf("expected linux to be functional, got %+v", envs[1])
+	}
+
+	mgr.Err = errors.New("connection refused")
+	envs = call()
+	if envs[1].Functional == nil || *envs[1].Functional || !strings.Contains(envs[1].Error, "-linux-socket") {
+		t.Errorf("expected linux to not be functional with an actionable error, got %+v", envs[1])
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	defaultEstimateSample = 2 * time.Second
+	maxEstimateSample     = 10 * time.Second
+)
+
+// runEstimate is the result of the estimate-run tool.
+type runEstimate struct {
+	Sample          string  `json:"sample"`
+	SampledEvents   int     `json:"sampledEvents"`
+	SampledBytes    int     `json:"sampledBytes"`
+	EventsPerSecond float64 `json:"eventsPerSecond"`
+	BytesPerSecond  float64 `json:"bytesPerSecond"`
+	Timeout         string  `json:"timeout"`
+	ExpectedEvents  int     `json:"expectedEvents"`
+	ExpectedBytes   int     `json:"expectedBytes"`
+	ResultLimit     int     `json:"resultLimit"`
+	Advice          string  `json:"advice"`
+}
+
+func (r *GadgetToolRegistry) newEstimateRunTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Estimates the volume of the results of a gadget run before running it: the gadget is run for a " +
+			"short sample and its event rate is extrapolated to the timeout. Use it before long runs or runs without " +
+			"filters to know whether the results would be truncated and a filter, fields or a shorter timeout are needed."),
+		mcp.WithString("image",
+			mcp.Required(),
+			mcp.Description("Gadget image e.g. trace_dns:latest"),
+		),
+		mcp.WithObject("params",
+			mcp.Description("key-value pairs of parameters to pass to the gadget, as for its tool"),
+		),
+		mcp.WithNumber("timeout",
+			mcp.Description("Timeout in seconds of the planned run (default the default gadget timeout)"),
+		),
+		mcp.WithNumber("sample",
+			mcp.Description(fmt.Sprintf("Duration in seconds of the sample run (default %d, at most %d)",
+				int(defaultEstimateSample.Seconds()), int(maxEstimateSample.Seconds()))),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+	}
+	tool := mcp.NewTool(
+		"estimate-run",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.estimateRunHandler,
+	}
+}
+
+func (r *GadgetToolRegistry) estimateRunHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	image := request.GetString("image", "")
+	if image == "" {
+		return mcp.NewToolResultError("an image is required"), nil
+	}
+	timeout := r.defaultTimeout
+	if t := request.GetInt("timeout", 0); t > 0 {
+		timeout = time.Duration(t) * time.Second
+	}
+	sample := defaultEstimateSample
+	if s := request.GetInt("sample", 0); s > 0 {
+		sample = min(time.Duration(s)*time.Second, maxEstimateSample)
+	}
+	sample = min(sample, timeout)
+
+	info, err := r.gadgetMgr.GetInfo(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("getting gadget info for %s: %w", image, err)
+	}
+	params := defaultParamsFromGadgetInfo(info)
+	if _, ok := params[namespaceParam]; ok {
+		if ns := r.getDefaultNamespace(); ns != "" {
+			params[namespaceParam] = ns
+		}
+	}
+	// Map gadgets only emit events every interval, make sure the sample sees some of them
+	if _, ok := params[mapFetchIntervalParam]; ok {
+		params[mapFetchIntervalParam] = (sample / 2).String()
+	}
+	if p, ok := request.GetArguments()["params"].(map[string]any); ok {
+		flat, err := flattenParams(p, r.nestedParams)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for k, v := range flat {
+			params[k] = v
+		}
+	}
+	r.applyGlobalFilter(ctx, info, params)
+	runParams, err := r.expandParamEnv(params)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	release, err := r.acquireRun(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	log.DebugContext(ctx, "Sampling gadget", "image", info.ImageName, "params", params, "sample", sample)
+	resp, err := r.gadgetMgr.Run(info.ImageName, runParams, sample, nil)
+	release()
+	if err != nil {
+		r.recordRunError(info.ImageName, params, nil, false, err)
+		return nil, fmt.Errorf("sampling gadget %s: %w", info.ImageName, err)
+	}
+
+	// The results of the session are truncated further once its budget is spent
+	resultLen, _ := r.sessionResultLen(ctx)
+	out, err := json.Marshal(estimateRun(resp.Events, len(resp.Output), resp.Duration, sample, timeout, resultLen))
+	if err != nil {
+		return nil, fmt.Errorf("marshalling run estimate: %w", err)
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// estimateRun extrapolates the events and bytes collected during elapsed to timeout. elapsed falls back to sample if
+// the runtime didn't report it.
+func estimateRun(events, bytes int, elapsed, sample, timeout time.Duration, resultLimit int) runEstimate {
+	if elapsed <= 0 {
+		elapsed = sample
+	}
+	e := runEstimate{
+		Sample:          sample.String(),
+		SampledEvents:   events,
+		SampledBytes:    bytes,
+		EventsPerSecond: float64(events) / elapsed.Seconds(),
+		BytesPerSecond:  float64(bytes) / elapsed.Seconds(),
+		Timeout:         timeout.String(),
+		ResultLimit:     resultLimit,
+	}
+	e.ExpectedEvents = int(e.EventsPerSecond * timeout.Seconds())
+	e.ExpectedBytes = int(e.BytesPerSecond * timeout.Seconds())
+	switch {
+	case events == 0:
+		e.Advice = "No events were collected during the sample, the run may produce few events: run it as planned, " +
+			"with a longer timeout if needed."
+	case e.ExpectedBytes <= resultLimit:
+		e.Advice = "The results should fit in the result limit, run it as planned."
+	default:
+		fit := time.Duration(float64(resultLimit) / e.BytesPerSecond * float64(time.Second)).Round(time.Second)
+		e.Advice = fmt.Sprintf("The results would be truncated to about %d%% of them. Add a filter, select fewer fields, "+
+			"aggregate the events or reduce the timeout to about %s.", max(1, resultLimit*100/e.ExpectedBytes), max(fit, time.Second))
+	}
+	return e
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestEstimateRun(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	mgr.Result.Duration = 2 * time.Second
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"image": "trace_dns:latest", "timeout": float64(60)}
+	res, err := r.estimateRunHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var e runEstimate
+	if err := json.Unmarshal([]byte(resultText(t, res)), &e); err != nil {
+		t.Fatalf("decoding estimate: %v", err)
+	}
+	if e.EventsPerSecond != 0.5 || e.ExpectedEvents != 30 || e.ExpectedBytes != 30*len(mgr.Result.Output) {
+		t.Errorf("unexpected estimate %+v", e)
+	}
+	if calls := mgr.RunCalls(); len(calls) != 1 || calls[0].Timeout != defaultEstimateSample {
+		t.Errorf("expected a single sample run of %s, got %+v", defaultEstimateSample, calls)
+	}
+
+	e = estimateRun(1000, 1024*1024, time.Second, time.Second, 10*time.Second, maxResultLen)
+	if !strings.Contains(e.Advice, "reduce the timeout to about 1s") {
+		t.Errorf("expected advice to reduce the timeout, got %q", e.Advice)
+	}
+}
+
+func TestEstimateRunSessionBudget(t *testing.T) {
+	r, _ := newTestRegistry(t, WithResultBudget(100))
+	r.resultBudget.add("", 100)
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"image": "trace_dns:latest"}
+	res, err := r.estimateRunHandler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var e runEstimate
+	if err := json.Unmarshal([]byte(resultText(t, res)), &e); err != nil {
+		t.Fatalf("decoding estimate: %v", err)
+	}
+	if e.ResultLimit != reducedResultLen {
+		t.Errorf("expected the reduced result limit of a session over its budget, got %d", e.ResultLimit)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+const sortParam = "operator.sort.sort"
+
+// hasParam reports whether the gadget accepts the given (prefixed) param.
+func hasParam(info *api.GadgetInfo, key string) bool {
+	for _, p := range info.Params {
+		if p.Prefix+p.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldNames returns the full names of the fields of all data sources of the gadget.
+func fieldNames(info *api.GadgetInfo) []string {
+	var names []string
+	for _, ds := range info.DataSources {
+		for _, f := range ds.Fields {
+			if !slices.Contains(names, f.FullName) {
+				names = append(names, f.FullName)
+			}
+		}
+	}
+	return names
+}
+
+// validateSort checks that all fields in a sort expression, e.g. "-count,comm" or "ds:-count", exist in the gadget.
+func validateSort(info *api.GadgetInfo, sortBy string) error {
+	names := fieldNames(info)
+	for _, rule := range strings.Split(sortBy, ";") {
+		fields := rule
+		if _, f, ok := strings.Cut(rule, ":"); ok {
+			fields = f
+		}
+		for _, field := range strings.Split(fields, ",") {
+			field = strings.TrimPrefix(strings.TrimSpace(field), "-")
+			if field == "" {
+				continue
+			}
+			if !slices.Contains(names, field) {
+				return fmt.Errorf("unknown sort field %q, valid fields are: %s", field, strings.Join(names, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// parseFields splits a comma-separated list of fields and checks that they exist in the gadget.
+func parseFields(info *api.GadgetInfo, fields string) ([]string, error) {
+	names := fieldNames(info)
+	var res []string
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !slices.Contains(names, field) {
+			return nil, fmt.Errorf("unknown field %q, valid fields are: %s", field, strings.Join(names, ", "))
+		}
+		res = append(res, field)
+	}
+	return res, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type dataSourceFields struct {
+	DataSource string      `json:"dataSource"`
+	Fields     []fieldInfo `json:"fields"`
+}
+
+type fieldInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+func (r *GadgetToolRegistry) newFieldsTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Lists the data sources of a gadget along with the names, types and descriptions of their fields. " +
+			"Use the returned field names when filtering or sorting gadget results."),
+		mcp.WithString("image",
+			mcp.Required(),
+			mcp.Description("Gadget image e.g. trace_dns:latest"),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"gadget-fields",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.fieldsHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) fieldsHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		image := request.GetString("image", "")
+		if image == "" {
+			return nil, fmt.Errorf("an image is required")
+		}
+
+		info, err := r.gadgetMgr.GetInfo(ctx, image)
+		if err != nil {
+			return nil, fmt.Errorf("getting gadget info for %s: %w", image, err)
+		}
+
+		res := make([]dataSourceFields, 0, len(info.DataSources))
+		for _, ds := range info.DataSources {
+			dsf := dataSourceFields{DataSource: ds.Name}
+			for _, f := range ds.Fields {
+				dsf.Fields = append(dsf.Fields, fieldInfo{
+					Name:        f.FullName,
+					Type:        f.Kind.String(),
+					Description: f.Annotations[metadatav1.DescriptionAnnotation],
+				})
+			}
+			res = append(res, dsf)
+		}
+		out, err := json.Marshal(res)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling fields: %w", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// filterParam is the param of the filter operator
+const filterParam = "operator.filter.filter"
+
+// filterOps are the operators supported by the filter operator, longest first so that e.g. '>=' isn't parsed as '>'
+var filterOps = []string{"==", "!=", "<=", ">=", "!~", "=", "<", ">", "~"}
+
+func (r *GadgetToolRegistry) newValidateFilterTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Checks a filter expression for a gadget without running it: its syntax, e.g. 'name==example.com.,qr!=Q', " +
+			"and that the fields it references exist. Returns 'ok' or the rule that is invalid."),
+		mcp.WithString("image",
+			mcp.Required(),
+			mcp.Description("Gadget image e.g. trace_dns:latest"),
+		),
+		mcp.WithString("filter",
+			mcp.Required(),
+			mcp.Description("Filter expression, as given to the operator.filter.filter param"),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"validate-filter",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.validateFilterHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) validateFilterHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		image := request.GetString("image", "")
+		if image == "" {
+			return nil, fmt.Errorf("an image is required")
+		}
+		filter := request.GetString("filter", "")
+		if filter == "" {
+			return nil, fmt.Errorf("a filter is required")
+		}
+
+		info, err := r.gadgetMgr.GetInfo(ctx, image)
+		if err != nil {
+			return nil, fmt.Errorf("getting gadget info for %s: %w", image, err)
+		}
+		if err := validateFilter(info, filter); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText("ok"), nil
+	}
+}
+
+// validateFilter checks a filter expression the way the filter operator parses it: comma-separated rules of the
+// form [dataSource:]field<op>value, where the field must exist in exactly one data source.
+func validateFilter(info *api.GadgetInfo, filter string) error {
+	for _, rule := range api.SplitStringWithEscape(filter, ',') {
+		if rule == "" {
+			continue
+		}
+		i := strings.IndexAny(rule, "!~><=")
+		if i < 0 {
+			return fmt.Errorf("invalid filter rule %q: missing operator", rule)
+		}
+		field, rest := rule[:i], rule[i:]
+		var op string
+		for _, o := range filterOps {
+			if strings.HasPrefix(rest, o) {
+				op = o
+				break
+			}
+		}
+		value := strings.TrimPrefix(rest, op)
+		if value == "" {
+			return fmt.Errorf("invalid filter rule %q: missing value", rule)
+		}
+		// The filter operator reads all operator characters, so e.g. '===' is invalid rather than '==' with value '='
+		if op == "" || strings.ContainsRune("!~><=", rune(value[0])) {
+			return fmt.Errorf("invalid filter rule %q: invalid operator in %q", rule, rest)
+		}
+		if op == "~" || op == "!~" {
+			if _, err := regexp.Compile(value); err != nil {
+				return fmt.Errorf("invalid filter rule %q: invalid regular expression %q: %w", rule, value, err)
+			}
+		}
+		if err := validateFilterField(info, field); err != nil {
+			return fmt.Errorf("invalid filter rule %q: %w", rule, err)
+		}
+	}
+	return nil
+}
+
+// validateFilterField checks that a field, optionally prefixed by its data source e.g. 'dns:name', exists in exactly
+// one data source.
+func validateFilterField(info *api.GadgetInfo, field string) error {
+	dsName, fieldName, ok := strings.Cut(field, ":")
+	if !ok {
+		dsName, fieldName = "", field
+	}
+	if fieldName == "" {
+		return fmt.Errorf("missing field")
+	}
+	found := 0
+	for _, ds := range info.DataSources {
+		if dsName != "" && ds.Name != dsName {
+			continue
+		}
+		for _, f := range ds.Fields {
+			if f.FullName == fieldName {
+				found++
+				break
+			}
+		}
+	}
+	switch {
+	case found == 0:
+		return fmt.Errorf("unknown field %q, valid fields are: %s", fieldName, strings.Join(fieldNames(info), ", "))
+	case found > 1:
+		return fmt.Errorf("ambiguous field %q, prefix it with its data source e.g. 'ds:%s'", fieldName, fieldName)
+	}
+	return nil
+}
+
+// WithGlobalFilter sets a filter expression applied to every gadget run along with the filter of the call, see
+// applyGlobalFilter.
+func WithGlobalFilter(filter string) Option {
+	return func(r *GadgetToolRegistry) {
+		r.globalFilter = filter
+	}
+}
+
+// applyGlobalFilter adds the rules of the global filter to the filter param. As rules are combined with a logical
+// AND, the filter of the call can only narrow the global filter, not override it. Rules on fields the gadget doesn't
+// have are skipped, so that e.g. a rule on k8s.namespace doesn't break the gadgets without Kubernetes enrichment.
+func (r *GadgetToolRegistry) applyGlobalFilter(ctx context.Context, info *api.GadgetInfo, params map[string]string) {
+	if r.globalFilter == "" {
+		return
+	}
+	var rules []string
+	for _, rule := range api.SplitStringWithEscape(r.globalFilter, ',') {
+		if rule == "" {
+			continue
+		}
+		rule = escapeFilterRule(rule)
+		if err := validateFilter(info, rule); err != nil {
+			log.WarnContext(ctx, "Skipping global filter rule", "image", info.ImageName, "error", err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return
+	}
+	if filter := params[filterParam]; filter != "" {
+		rules = append(rules, filter)
+	}
+	params[filterParam] = strings.Join(rules, ",")
+}
+
+// escapeFilterRule reverts the unescaping done by api.SplitStringWithEscape.
+func escapeFilterRule(rule string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`).Replace(rule)
+}
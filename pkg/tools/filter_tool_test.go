@@ -0,0 +1,74 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateFilter(t *testing.T) {
+	info := testGadgetInfo()
+	for filter, expectedErr := range map[string]string{
+		"name==example.com.":      "",
+		"dns:name~^example,qr!=Q": "",
+		"name=example.com.,qr>=Q": "",
+		"unknown==x":              "unknown field \"unknown\"",
+		"other:name==x":           "unknown field \"name\"",
+		"name":                    "missing operator",
+		"name==":                  "missing value",
+		"name===x":                "invalid operator",
+		"name~(":                  "invalid regular expression",
+		"qr==Q,name!~[":           "invalid regular expression",
+		"name==a\\,b,qr==R":       "",
+	} {
+		err := validateFilter(info, filter)
+		switch {
+		case expectedErr == "" && err != nil:
+			t.Errorf("expected filter %q to be valid, got %v", filter, err)
+		case expectedErr != "" && (err == nil || !strings.Contains(err.Error(), expectedErr)):
+			t.Errorf("expected filter %q to fail with %q, got %v", filter, expectedErr, err)
+		}
+	}
+}
+
+func TestGlobalFilter(t *testing.T) {
+	r, mgr := newTestRegistry(t, WithGlobalFilter("qr==Q,k8s.namespace!=gadget"))
+
+	tests := []struct {
+		args     map[string]any
+		expected string
+	}{
+		{map[string]any{}, "qr==Q"},
+		{map[string]any{"params": map[string]any{filterParam: "name==example.com."}}, "qr==Q,name==example.com."},
+		{map[string]any{"params": map[string]any{filterParam: "qr!=Q"}}, "qr==Q,qr!=Q"},
+	}
+	for _, tt := range tests {
+		if _, err := callTool(t, r, tt.args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		calls := mgr.RunCalls()
+		if got := calls[len(calls)-1].Params[filterParam]; got != tt.expected {
+			t.Errorf("expected filter %q for args %v, got %q", tt.expected, tt.args, got)
+		}
+	}
+
+	if _, err := callTool(t, r, map[string]any{"background": true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mgr.DetachedCalls()[0].Params[filterParam]; got != "qr==Q" {
+		t.Errorf("expected the global filter to be applied to background runs, got %q", got)
+	}
+}
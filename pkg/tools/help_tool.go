@@ -0,0 +1,120 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+var helpTmpl = template.Must(template.New("gadgetHelp.tmpl").Funcs(template.FuncMap{
+	// cell escapes a value so it can be used in a markdown table cell
+	"cell": func(s string) string {
+		return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+	},
+}).ParseFS(templates, "templates/gadgetHelp.tmpl"))
+
+func (r *GadgetToolRegistry) newHelpTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Returns the documentation of a gadget as markdown: its params (name, type, default, description, possible values) and output fields. " +
+			"Use it to show the user how a gadget can be configured."),
+		mcp.WithString("image",
+			mcp.Required(),
+			mcp.Description("Gadget image e.g. trace_dns:latest"),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"gadget-help",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.helpHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) helpHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		image := request.GetString("image", "")
+		if image == "" {
+			return nil, fmt.Errorf("an image is required")
+		}
+
+		info, err := r.gadgetMgr.GetInfo(ctx, image)
+		if err != nil {
+			return nil, fmt.Errorf("getting gadget info for %s: %w", image, err)
+		}
+		out, err := renderHelp(info, r.environment())
+		if err != nil {
+			return nil, err
+		}
+		if limit := r.resultLen(); len(out) > limit {
+			out = out[:limit] + "\n\n(truncated)"
+		}
+		return mcp.NewToolResultText(out), nil
+	}
+}
+
+// renderHelp renders the documentation of the gadget described by info for the given environment.
+func renderHelp(info *api.GadgetInfo, env string) (string, error) {
+	var metadata *metadatav1.GadgetMetadata
+	if err := yaml.Unmarshal(info.Metadata, &metadata); err != nil {
+		return "", fmt.Errorf("unmarshalling gadget metadata: %w", err)
+	}
+
+	td := ToolData{
+		Name:        info.ImageName,
+		Environment: environmentTitle(env),
+	}
+	if metadata != nil {
+		td.Name = metadata.Name
+		td.Description = metadata.Description
+	}
+	for _, p := range info.Params {
+		td.Params = append(td.Params, ParamData{
+			Name:           p.Prefix + p.Key,
+			Type:           p.TypeHint,
+			DefaultValue:   p.DefaultValue,
+			Description:    p.Description,
+			PossibleValues: strings.Join(p.PossibleValues, ", "),
+		})
+	}
+	for _, ds := range info.DataSources {
+		for _, f := range ds.Fields {
+			td.Fields = append(td.Fields, FieldData{
+				Name:           f.FullName,
+				Type:           f.Kind.String(),
+				Description:    f.Annotations[metadatav1.DescriptionAnnotation],
+				PossibleValues: f.Annotations[metadatav1.ValueOneOfAnnotation],
+			})
+		}
+	}
+
+	var out bytes.Buffer
+	if err := helpTmpl.Execute(&out, td); err != nil {
+		return "", fmt.Errorf("executing help template for gadget %s: %w", info.ImageName, err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHelpResultLimit(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	info := testGadgetInfo()
+	info.Metadata = []byte("name: trace dns\ndescription: " + strings.Repeat("x", 2*minResultLimit) + "\n")
+	mgr.Infos[info.ImageName] = info
+	r.resultLimit.Store(minResultLimit)
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"image": info.ImageName}
+	res, err := r.helpHandler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); len(text) > minResultLimit+len("\n\n(truncated)") || !strings.HasSuffix(text, "(truncated)") {
+		t.Errorf("expected the help truncated to the result limit, got %d bytes", len(text))
+	}
+}
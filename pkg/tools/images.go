@@ -0,0 +1,107 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// imageRefRegex loosely matches an image reference: an optional registry host (with port), a repository path,
+// an optional tag and an optional digest.
+var imageRefRegex = regexp.MustCompile(`^([a-zA-Z0-9.-]+(:[0-9]+)?/)?[a-z0-9]+([._/-][a-z0-9]+)*(:[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127})?(@sha256:[a-f0-9]{64})?$`)
+
+// IsValidImageRef reports whether image looks like a valid gadget image reference.
+func IsValidImageRef(image string) bool {
+	return imageRefRegex.MatchString(image)
+}
+
+// NormalizeImageRef trims image and lowercases its digest, if any, so a digest-pinned image (e.g.
+// trace_dns@sha256:...) is validated and registered the same way regardless of how it was written. Tags are kept as
+// they are, as they are case-sensitive.
+func NormalizeImageRef(image string) string {
+	image = strings.TrimSpace(image)
+	if name, digest, ok := strings.Cut(image, "@"); ok {
+		return name + "@" + strings.ToLower(digest)
+	}
+	return image
+}
+
+// validateImages returns the normalized images with a valid reference, either tagged, digest-pinned or both. Invalid
+// ones are reported as a single error in strict mode, or logged and dropped otherwise.
+func (r *GadgetToolRegistry) validateImages(images []string) ([]string, error) {
+	var valid, invalid []string
+	for _, img := range images {
+		img = NormalizeImageRef(img)
+		if IsValidImageRef(img) {
+			valid = append(valid, img)
+		} else {
+			invalid = append(invalid, img)
+		}
+	}
+	if len(invalid) == 0 {
+		return valid, nil
+	}
+	if r.strictImages {
+		return nil, fmt.Errorf("invalid gadget images: %s", strings.Join(invalid, ", "))
+	}
+	log.Warn("Skipping invalid gadget images", "images", invalid)
+	return valid, nil
+}
+
+// splitImageVersion splits image into its repository and its version, the tag or else a short digest, e.g. trace_dns
+// and v0.40.0 for trace_dns:v0.40.0. The version is empty for an image with neither.
+func splitImageVersion(image string) (string, string) {
+	name, digest, hasDigest := strings.Cut(image, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		return name[:i], name[i+1:]
+	}
+	if hasDigest {
+		digest = strings.TrimPrefix(digest, "sha256:")
+		return name, digest[:min(len(digest), 12)]
+	}
+	return name, ""
+}
+
+// multiVersionImages returns the images whose repository is listed with different versions, e.g. trace_dns:v0.40.0
+// and trace_dns:v0.41.0, so their tools can be told apart by version.
+func multiVersionImages(images []string) map[string]bool {
+	versions := make(map[string][]string)
+	for _, img := range images {
+		repo, version := splitImageVersion(img)
+		if !slices.Contains(versions[repo], version) {
+			versions[repo] = append(versions[repo], version)
+		}
+	}
+	multi := make(map[string]bool)
+	for _, img := range images {
+		repo, _ := splitImageVersion(img)
+		if len(versions[repo]) > 1 {
+			multi[img] = true
+		}
+	}
+	return multi
+}
+
+// versionedToolName appends the version of image to name, e.g. trace_dns_v0_40_0 for trace_dns:v0.40.0.
+func versionedToolName(name, image string) string {
+	_, version := splitImageVersion(image)
+	if version == "" {
+		return name
+	}
+	return normalizeToolName(name + "_" + version)
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeImageRef(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	for _, tc := range []struct {
+		image    string
+		expected string
+		valid    bool
+	}{
+		{image: "trace_dns:latest", expected: "trace_dns:latest", valid: true},
+		{image: " trace_dns@sha256:" + digest + " ", expected: "trace_dns@sha256:" + digest, valid: true},
+		{image: "trace_dns@SHA256:" + strings.ToUpper(digest), expected: "trace_dns@sha256:" + digest, valid: true},
+		{image: "ghcr.io/inspektor-gadget/gadget/trace_dns:v0.41.0@sha256:" + digest, expected: "ghcr.io/inspektor-gadget/gadget/trace_dns:v0.41.0@sha256:" + digest, valid: true},
+		{image: "trace_dns@sha256:abc", expected: "trace_dns@sha256:abc", valid: false},
+	} {
+		normalized := NormalizeImageRef(tc.image)
+		if normalized != tc.expected {
+			t.Errorf("expected %q to be normalized to %q, got %q", tc.image, tc.expected, normalized)
+		}
+		if valid := IsValidImageRef(normalized); valid != tc.valid {
+			t.Errorf("expected IsValidImageRef(%q) to be %v", normalized, tc.valid)
+		}
+	}
+}
+
+func TestSplitImageVersion(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	tests := []struct {
+		image   string
+		repo    string
+		version string
+	}{
+		{image: "trace_dns:v0.41.0", repo: "trace_dns", version: "v0.41.0"},
+		{image: "ghcr.io/inspektor-gadget/gadget/trace_dns@" + digest, repo: "ghcr.io/inspektor-gadget/gadget/trace_dns", version: "abababababab"},
+		{image: "localhost:5000/trace_dns", repo: "localhost:5000/trace_dns"},
+	}
+	for _, tt := range tests {
+		if repo, version := splitImageVersion(tt.image); repo != tt.repo || version != tt.version {
+			t.Errorf("expected repository %q and version %q for %s, got %q and %q", tt.repo, tt.version, tt.image, repo, version)
+		}
+	}
+}
@@ -16,13 +16,16 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
 )
 
-func newIsDeployedTool() server.ServerTool {
+func (r *GadgetToolRegistry) newIsDeployedTool() server.ServerTool {
 	opts := []mcp.ToolOption{
 		mcp.WithDescription("Check if Inspektor Gadget is deployed on the target system. Doesn't rely on if mcp server deployed it or not but checks if the Inspektor Gadget resources are present in the cluster."),
 		mcp.WithReadOnlyHintAnnotation(true),
@@ -34,17 +37,20 @@ func newIsDeployedTool() server.ServerTool {
 
 	return server.ServerTool{
 		Tool:    tool,
-		Handler: isDeployedHandler,
+		Handler: r.isDeployedHandler,
 	}
 }
 
-func isDeployedHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	isDeployed, ns, err := isInspektorGadgetDeployed(ctx)
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+func (r *GadgetToolRegistry) isDeployedHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if env := r.environment(); env != deployer.KubernetesEnv {
+		return mcp.NewToolResultError(fmt.Sprintf("Inspektor Gadget is only deployed in the Kubernetes environment, not in the %s one", env)), nil
 	}
-	if !isDeployed {
+	ns, err := r.inspektorGadgetNamespace(ctx)
+	if errors.Is(err, ErrNotDeployed) {
 		return mcp.NewToolResultError("Inspektor Gadget is not deployed"), nil
 	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	return mcp.NewToolResultText(fmt.Sprintf("Inspektor Gadget is deployed in namespace %s", ns)), nil
 }
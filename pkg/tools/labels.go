@@ -0,0 +1,55 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Limits of the labels attached to a gadget run
+const (
+	maxLabels        = 16
+	maxLabelValueLen = 256
+)
+
+// labelKeyRegex matches the allowed label keys, e.g. incident or team.example.com/owner
+var labelKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9._/-]{0,61}[a-zA-Z0-9])?$`)
+
+// parseLabels validates the labels argument of a gadget tool, an object of string values.
+func parseLabels(v any) (map[string]string, error) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("labels must be an object of string values")
+	}
+	if len(obj) > maxLabels {
+		return nil, fmt.Errorf("too many labels: %d, at most %d are allowed", len(obj), maxLabels)
+	}
+	labels := make(map[string]string, len(obj))
+	for k, v := range obj {
+		if !labelKeyRegex.MatchString(k) {
+			return nil, fmt.Errorf("invalid label key %q: it must be at most 63 alphanumeric characters, '.', '_', '/' or '-'", k)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("the value of label %q must be a string", k)
+		}
+		if len(s) > maxLabelValueLen {
+			return nil, fmt.Errorf("the value of label %q is longer than %d bytes", k, maxLabelValueLen)
+		}
+		labels[k] = s
+	}
+	return labels, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestHandlerLabels(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	labels := map[string]any{"incident": "INC-123"}
+
+	res, err := callTool(t, r, map[string]any{"labels": labels})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := res.Content[0].(mcp.TextContent).Text; !strings.Contains(text, `"labels":{"incident":"INC-123"}`) {
+		t.Errorf("expected the labels in the result metadata, got %s", text)
+	}
+
+	if _, err := callTool(t, r, map[string]any{"labels": labels, "background": true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	instances, err := mgr.ListInstances(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(instances) != 1 || instances[0].Labels["incident"] != "INC-123" {
+		t.Errorf("expected the labels to be stored with the instance, got %+v", instances)
+	}
+
+	for _, invalid := range []any{"incident=INC-123", map[string]any{"incident": 123}, map[string]any{"bad key": "x"}} {
+		res, err := callTool(t, r, map[string]any{"labels": invalid})
+		if err != nil || !res.IsError {
+			t.Errorf("expected a tool error for labels %v, got %v, %v", invalid, res, err)
+		}
+	}
+}
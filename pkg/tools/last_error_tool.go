@@ -0,0 +1,106 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// runError is the last error of running a gadget image, reported by the last-error tool.
+type runError struct {
+	Image      string            `json:"image"`
+	Params     map[string]string `json:"params"`
+	Nodes      []string          `json:"nodes,omitempty"`
+	Background bool              `json:"background"`
+	Error      string            `json:"error"`
+	Time       time.Time         `json:"time"`
+}
+
+// recordRunError keeps err as the last error of running image, replacing the previous one.
+func (r *GadgetToolRegistry) recordRunError(image string, params map[string]string, nodes []string, background bool, err error) {
+	r.lastErrMu.Lock()
+	defer r.lastErrMu.Unlock()
+	if r.lastErrors == nil {
+		r.lastErrors = make(map[string]runError)
+	}
+	r.lastErrors[image] = runError{
+		Image:      image,
+		Params:     params,
+		Nodes:      nodes,
+		Background: background,
+		Error:      err.Error(),
+		Time:       time.Now(),
+	}
+}
+
+// lastRunError returns the last error of running image, or the most recent one of all images if image is empty.
+func (r *GadgetToolRegistry) lastRunError(image string) (runError, bool) {
+	r.lastErrMu.Lock()
+	defer r.lastErrMu.Unlock()
+	if image != "" {
+		e, ok := r.lastErrors[image]
+		return e, ok
+	}
+	var last runError
+	for _, e := range r.lastErrors {
+		if e.Time.After(last.Time) {
+			last = e
+		}
+	}
+	return last, !last.Time.IsZero()
+}
+
+func (r *GadgetToolRegistry) newLastErrorTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Returns the last error of running a gadget, with its image, params and time, to debug a " +
+			"failing gadget without running it again."),
+		mcp.WithString("image",
+			mcp.Description("Gadget image e.g. trace_dns:latest, by default the most recent error of all gadgets is returned"),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"last-error",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.lastErrorHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) lastErrorHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		image := request.GetString("image", "")
+		e, ok := r.lastRunError(image)
+		if !ok {
+			if image != "" {
+				return mcp.NewToolResultText(fmt.Sprintf("No gadget run of %s has failed", image)), nil
+			}
+			return mcp.NewToolResultText("No gadget run has failed"), nil
+		}
+		out, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling last error: %w", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
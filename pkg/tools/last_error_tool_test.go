@@ -0,0 +1,56 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestLastError(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	if _, ok := r.lastRunError(""); ok {
+		t.Fatal("expected no last error before any run")
+	}
+
+	mgr.Err = errors.New("pull failed")
+	if _, err := callTool(t, r, map[string]any{"params": map[string]any{"operator.KubeManager.namespace": "default"}}); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+	e, ok := r.lastRunError("")
+	if !ok {
+		t.Fatal("expected a last error after a failed run")
+	}
+	if e.Image != "trace_dns:latest" || e.Error != "pull failed" || e.Params["operator.KubeManager.namespace"] != "default" || e.Background {
+		t.Errorf("unexpected last error: %+v", e)
+	}
+	if _, ok := r.lastRunError("other:latest"); ok {
+		t.Errorf("expected no last error for another image")
+	}
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"image": "trace_dns:latest"}
+	res, err := r.lastErrorHandler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("calling last-error: %v", err)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "pull failed") {
+		t.Errorf("expected the last error in the result, got %q", text)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
+)
+
+// WithLazyGadgetTools registers the gadget tools with a minimal schema derived from their image, without getting the
+// gadget info on startup. The info is fetched on the first call of a tool, which is then replaced by the full one.
+// It makes the startup faster and tolerant to a backend being unavailable, at the cost of a less descriptive tool
+// until its first call.
+func WithLazyGadgetTools(lazy bool) Option {
+	return func(r *GadgetToolRegistry) {
+		r.lazyGadgetTools = lazy
+	}
+}
+
+// registerLazyGadgets registers a lazy tool for each image, see WithLazyGadgetTools.
+func (r *GadgetToolRegistry) registerLazyGadgets(images []string) error {
+	var skipped []string
+	versioned := multiVersionImages(images)
+	for i, img := range images {
+		if r.maxGadgetTools > 0 && i >= r.maxGadgetTools {
+			skipped = append(skipped, images[i:]...)
+			break
+		}
+		name := toolName(&api.GadgetInfo{ImageName: img}, &metadatav1.GadgetMetadata{})
+		if name == "" {
+			if r.strictImages {
+				return fmt.Errorf("%s: %w", img, errEmptyToolName)
+			}
+			log.Warn("Skipping gadget image without a tool name", "image", img)
+			continue
+		}
+		if versioned[img] {
+			name = versionedToolName(name, img)
+		}
+		name = r.uniqueToolName(name, img)
+		log.Debug("Adding lazy tool", "image", img, "name", name)
+		r.tools[img] = r.lazyTool(img, name)
+	}
+	if len(skipped) > 0 {
+		log.Info("Skipping gadget images over the gadget tools limit", "limit", r.maxGadgetTools, "images", skipped)
+	}
+	return nil
+}
+
+func (r *GadgetToolRegistry) lazyTool(image, name string) server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription(fmt.Sprintf("Runs the %s gadget (image %s). Its params and output fields are only known "+
+			"after the first call, use the gadget-help tool with the image to get them before.", name, image)),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithObject("params",
+			mcp.Description("key-value pairs of parameters to pass to the gadget"),
+		),
+		mcp.WithNumber("timeout",
+			mcp.Description(fmt.Sprintf("Timeout in seconds for the gadget to run (default %d)", int(r.defaultTimeout.Seconds()))),
+		),
+		mcp.WithBoolean("background",
+			mcp.Description("Run in background, allowing the gadget run continuously until stopped"),
+		),
+	}
+	if r.environment() == deployer.KubernetesEnv {
+		opts = append(opts, mcp.WithString("node",
+			mcp.Description("Comma-separated list of nodes to run the gadget on, by default it runs on all nodes"),
+		))
+	}
+	tool := mcp.NewTool(
+		name,
+		opts...,
+	)
+
+	var mu sync.Mutex
+	var handler server.ToolHandlerFunc
+	return server.ServerTool{
+		Tool: tool,
+		Handler: func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			mu.Lock()
+			if handler == nil {
+				h, err := r.resolveLazyTool(ctx, image, name)
+				if err != nil {
+					mu.Unlock()
+					return nil, err
+				}
+				handler = h
+			}
+			mu.Unlock()
+			return handler(ctx, request)
+		},
+	}
+}
+
+// resolveLazyTool gets the gadget info of image and replaces its lazy tool by the full one, keeping its name.
+func (r *GadgetToolRegistry) resolveLazyTool(ctx context.Context, image, name string) (server.ToolHandlerFunc, error) {
+	infoCtx, cancel := context.WithTimeout(ctx, r.infoTimeout)
+	defer cancel()
+	info, err := r.gadgetMgr.GetInfo(infoCtx, image)
+	if err != nil {
+		return nil, fmt.Errorf("getting gadget info for %s: %w", image, err)
+	}
+	t, err := r.toolFromGadgetInfo(info)
+	if err != nil {
+		return nil, fmt.Errorf("creating tool from gadget info for %s: %w", image, err)
+	}
+	t.Name = name
+	h := r.handlerFromGadgetInfo(info)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	log.DebugContext(ctx, "Replacing lazy tool", "image", image, "name", name)
+	r.tools[image] = server.ServerTool{Tool: t, Handler: h}
+	for _, callback := range r.callbacks {
+		log.Debug("Invoking tool registry callback", "tools_count", len(r.tools))
+		callback(r.all()...)
+	}
+	return h, nil
+}
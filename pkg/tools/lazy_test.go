@@ -0,0 +1,75 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestLazyGadgetTools(t *testing.T) {
+	r, mgr := newTestRegistry(t, WithLazyGadgetTools(true))
+	image := testGadgetInfo().ImageName
+	if err := r.registerGadgets(context.Background(), []string{image}); err != nil {
+		t.Fatalf("registering gadgets: %v", err)
+	}
+	var notified []server.ServerTool
+	r.RegisterCallback(func(tools ...server.ServerTool) {
+		notified = tools
+	})
+
+	lazy := r.tools[image]
+	if lazy.Tool.Name != "trace_dns" {
+		t.Fatalf("expected lazy tool %q, got %q", "trace_dns", lazy.Tool.Name)
+	}
+	if _, ok := lazy.Tool.InputSchema.Properties["sort"]; ok {
+		t.Errorf("expected the lazy tool to not know the gadget params")
+	}
+
+	var request mcp.CallToolRequest
+	if _, err := lazy.Handler(context.Background(), request); err != nil {
+		t.Fatalf("calling lazy tool: %v", err)
+	}
+	if len(mgr.RunCalls()) != 1 {
+		t.Errorf("expected the call to run the gadget, got %d runs", len(mgr.RunCalls()))
+	}
+	full := r.tools[image]
+	if _, ok := full.Tool.InputSchema.Properties["sort"]; !ok || full.Tool.Name != lazy.Tool.Name {
+		t.Errorf("expected the lazy tool to be replaced by the full one with the same name, got %v", full.Tool)
+	}
+	if len(notified) == 0 {
+		t.Errorf("expected the callbacks to be invoked with the full tool")
+	}
+}
+
+func TestVersionedToolNames(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	images := []string{"trace_dns:v0.40.0", "trace_dns:v0.41.0", "top_file:latest", "top_file:latest"}
+	if err := r.registerLazyGadgets(images); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for img, expected := range map[string]string{
+		"trace_dns:v0.40.0": "trace_dns_v0_40_0",
+		"trace_dns:v0.41.0": "trace_dns_v0_41_0",
+		"top_file:latest":   "top_file",
+	} {
+		if name := r.tools[img].Tool.Name; name != expected {
+			t.Errorf("expected tool name %q for %s, got %q", expected, img, name)
+		}
+	}
+}
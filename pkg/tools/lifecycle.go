@@ -2,10 +2,15 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
 )
 
 func (r *GadgetToolRegistry) newStopTool() server.ServerTool {
@@ -14,7 +19,7 @@ func (r *GadgetToolRegistry) newStopTool() server.ServerTool {
 		mcp.WithString("id",
 			mcp.Description("ID of the running gadget"),
 		),
-		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithReadOnlyHintAnnotation(false),
 	}
 	tool := mcp.NewTool(
 		"stop-gadget",
@@ -37,6 +42,7 @@ func (r *GadgetToolRegistry) stopHandler() server.ToolHandlerFunc {
 		if err != nil {
 			return nil, fmt.Errorf("failed to stop gadget with id %q: %w", id, err)
 		}
+		r.forgetBackgroundRun(id)
 		return mcp.NewToolResultText(fmt.Sprintf("Gadget with ID %q has been stopped", id)), nil
 	}
 }
@@ -47,6 +53,13 @@ func (r *GadgetToolRegistry) newGetResultsTool() server.ServerTool {
 		mcp.WithString("id",
 			mcp.Description("ID of the running gadget instance"),
 		),
+		mcp.WithString("since",
+			mcp.Description("Only return events newer than this duration e.g. '5m'. Events are filtered by their 'timestamp' field, "+
+				"events without it are always returned"),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("Cursor from the metadata of a previous call, only events newer than the ones returned by that call are returned"),
+		),
 		mcp.WithReadOnlyHintAnnotation(true),
 	}
 	tool := mcp.NewTool(
@@ -66,10 +79,222 @@ func (r *GadgetToolRegistry) getResultsHandler() server.ToolHandlerFunc {
 			return nil, fmt.Errorf("an id is required")
 		}
 
-		resp, err := r.gadgetMgr.Results(id)
+		var since time.Duration
+		if s := request.GetString("since", ""); s != "" {
+			var err error
+			since, err = time.ParseDuration(s)
+			if err != nil || since <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid since %q: must be a positive duration e.g. '5m'", s)), nil
+			}
+		}
+
+		cursor := request.GetString("cursor", "")
+		resp, err := r.gadgetMgr.Results(id, cursor)
 		if err != nil {
 			return nil, fmt.Errorf("attaching to gadget %s: %w", id, err)
 		}
-		return mcp.NewToolResultText(truncateResults(resp)), nil
+		if since > 0 {
+			var ok bool
+			resp, ok = filterSince(resp, time.Now().Add(-since))
+			if !ok {
+				return mcp.NewToolResultText("The gadget events don't have a timestamp field, all events are returned.\n" + r.formatResults(ctx, resp)), nil
+			}
+		}
+		if err := r.limitCursor(ctx, resp, cursor); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(r.formatResults(ctx, resp)), nil
+	}
+}
+
+func (r *GadgetToolRegistry) newListAllInstancesTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Lists all gadget instances running on the cluster, including the ones not started by this server. " +
+			"The managed field tells if an instance was started by this server."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"list-all-gadget-instances",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.listAllInstancesHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) listAllInstancesHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		instances, err := r.gadgetMgr.ListInstances(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing gadget instances: %w", err)
+		}
+		if len(instances) == 0 {
+			return mcp.NewToolResultText("No gadget instances are running"), nil
+		}
+		out, err := json.Marshal(instances)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling gadget instances: %w", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
+
+func (r *GadgetToolRegistry) newGadgetStatusTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Returns the state of a gadget instance with a specific ID: 'running', or 'stopped' if it was started by this " +
+			"server but isn't running anymore, e.g. because it failed. Also returns its image, creation time and nodes. Use get-results to get its events."),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("ID of the gadget instance"),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"gadget-status",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.gadgetStatusHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) gadgetStatusHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id := request.GetString("id", "")
+		if id == "" {
+			return nil, fmt.Errorf("an id is required")
+		}
+
+		instance, err := r.gadgetMgr.InstanceStatus(ctx, id)
+		if errors.Is(err, gadgetmanager.ErrInstanceNotFound) {
+			return mcp.NewToolResultError(fmt.Sprintf("No gadget instance with ID %q was found, use list-all-gadget-instances to list them", id)), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("getting status of gadget %s: %w", id, err)
+		}
+		out, err := json.Marshal(instance)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling gadget instance: %w", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
+
+func (r *GadgetToolRegistry) newTailResultsTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Returns the events collected by a gadget instance with a specific ID since the previous call of this " +
+			"tool for the same ID, or all of them on the first call. Use it to follow a gadget running in the background. " +
+			"Events are told apart by their timestamp, events without one are returned again on every call."),
+		mcp.WithString("id",
+			mcp.Required(),
+			mcp.Description("ID of the running gadget instance"),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"tail-results",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.tailResultsHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) tailResultsHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id := request.GetString("id", "")
+		if id == "" {
+			return nil, fmt.Errorf("an id is required")
+		}
+
+		cursor := r.gadgetMgr.TailCursor(id)
+		resp, err := r.gadgetMgr.Results(id, cursor)
+		if err != nil {
+			return nil, fmt.Errorf("attaching to gadget %s: %w", id, err)
+		}
+		if err := r.limitCursor(ctx, resp, cursor); err != nil {
+			return nil, err
+		}
+
+		// The cursor is stored by the manager so that it's forgotten along with the instance
+		var notice string
+		err = r.gadgetMgr.SetTailCursor(id, resp.Cursor)
+		if errors.Is(err, gadgetmanager.ErrInstanceNotFound) {
+			notice = "The gadget instance wasn't started by this server, the next call returns all events again. " +
+				"Use get-results with the cursor of the metadata to only get new events.\n"
+		} else if err != nil {
+			return nil, fmt.Errorf("storing cursor of gadget %s: %w", id, err)
+		}
+
+		if resp.Events == 0 {
+			return mcp.NewToolResultText(notice + fmt.Sprintf("No new events from gadget with ID %s", id)), nil
+		}
+		return mcp.NewToolResultText(notice + r.formatResults(ctx, resp)), nil
+	}
+}
+
+// limitCursor sets the cursor of result, the events returned by Results from cursor, to the last event that fits in
+// the results returned to the session of ctx, so that the events truncated away are returned by the next call.
+func (r *GadgetToolRegistry) limitCursor(ctx context.Context, result *gadgetmanager.RunResult, cursor string) error {
+	maxLen, _ := r.sessionResultLen(ctx)
+	kept, truncated := truncateOutput(result.Output, maxLen)
+	if !truncated {
+		return nil
+	}
+	// An event cut in the middle counts as returned, it would be cut the same way on the next call otherwise
+	lines := gadgetmanager.EventLines(result.Output)[:len(gadgetmanager.EventLines(kept))]
+	next, err := gadgetmanager.NextCursor(cursor, lines)
+	if err != nil {
+		return err
+	}
+	result.Cursor = next
+	return nil
+}
+
+func (r *GadgetToolRegistry) newCancelRunTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Cancels a gadget running in the foreground, its call then returns the events collected so far. " +
+			"Without an ID, the only foreground run in progress is cancelled, or the runs in progress are listed if there are several."),
+		mcp.WithString("id",
+			mcp.Description("ID of the foreground run to cancel"),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+	}
+	tool := mcp.NewTool(
+		"cancel-run",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.cancelRunHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) cancelRunHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id := request.GetString("id", "")
+		if id == "" {
+			runs := r.gadgetMgr.ForegroundRuns()
+			switch len(runs) {
+			case 0:
+				return mcp.NewToolResultText("No gadget is running in the foreground"), nil
+			case 1:
+				id = runs[0].ID
+			default:
+				out, err := json.Marshal(runs)
+				if err != nil {
+					return nil, fmt.Errorf("marshalling foreground runs: %w", err)
+				}
+				return mcp.NewToolResultText("Several gadgets are running in the foreground, call this tool again with the ID of one of them: " + string(out)), nil
+			}
+		}
+
+		if err := r.gadgetMgr.CancelRun(id); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("The foreground run with ID %s has been cancelled, it returns the events collected so far", id)), nil
 	}
 }
@@ -0,0 +1,98 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+// resultEvents returns the n field of the complete events between the results tags of a tool result.
+func resultEvents(t *testing.T, text string) []int {
+	t.Helper()
+	_, results, _ := strings.Cut(text, "<results>")
+	results, _, _ = strings.Cut(results, "</results>")
+	var events []int
+	for _, line := range gadgetmanager.EventLines(results) {
+		if line == "…" {
+			// Marks truncated results
+			continue
+		}
+		var event struct {
+			N int `json:"n"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("expected complete events, got %q: %v", line, err)
+		}
+		events = append(events, event.N)
+	}
+	return events
+}
+
+func TestTailResultsTruncated(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	r.resultLimit.Store(minResultLimit)
+
+	// Pairs of events share their timestamp, so that truncating can fall between them
+	const total = 60
+	var output strings.Builder
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range total {
+		fmt.Fprintf(&output, "{\"timestamp\":%q,\"n\":%d}\n", base.Add(time.Duration(i/2)*time.Second).Format(time.RFC3339Nano), i)
+	}
+	mgr.Result = &gadgetmanager.RunResult{Output: output.String(), Events: total}
+	id, err := mgr.RunDetached(testGadgetInfo().ImageName, nil, nil)
+	if err != nil {
+		t.Fatalf("starting instance: %v", err)
+	}
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"id": id}
+	var got []int
+	for range 10 {
+		res, err := r.tailResultsHandler()(context.Background(), request)
+		if err != nil || res.IsError {
+			t.Fatalf("tailing results: %v, %v", res, err)
+		}
+		text := resultText(t, res)
+		if strings.Contains(text, "No new events") {
+			break
+		}
+		got = append(got, resultEvents(t, text)...)
+	}
+	if len(got) != total {
+		t.Fatalf("expected %d events over the calls, got %d: %v", total, len(got), got)
+	}
+	for i, n := range got {
+		if n != i {
+			t.Fatalf("expected each event once and in order, got %v", got)
+		}
+	}
+
+	if err := mgr.Stop(id); err != nil {
+		t.Fatalf("stopping instance: %v", err)
+	}
+	if cursor := mgr.TailCursor(id); cursor != "" {
+		t.Errorf("expected the cursor to be forgotten with the instance, got %q", cursor)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+const (
+	// defaultActivityImage is run by namespace-activity, process executions are a cheap signal of activity
+	defaultActivityImage   = "trace_exec:latest"
+	defaultActivityTimeout = 5 * time.Second
+	maxActivityTimeout     = 30 * time.Second
+	allNamespacesParam     = "operator.KubeManager.all-namespaces"
+	namespaceField         = "k8s.namespace"
+)
+
+type namespaceActivity struct {
+	Gadget     string                   `json:"gadget"`
+	Window     string                   `json:"window"`
+	Events     int                      `json:"events"`
+	Namespaces []namespaceActivityEntry `json:"namespaces"`
+	// HostEvents are the events that don't belong to a Kubernetes namespace, e.g. from processes of the nodes
+	HostEvents int `json:"hostEvents,omitempty"`
+}
+
+type namespaceActivityEntry struct {
+	Namespace string `json:"namespace"`
+	Events    int    `json:"events"`
+}
+
+func (r *GadgetToolRegistry) newNamespaceActivityTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Reports which namespaces currently have activity, by running a gadget across all namespaces " +
+			"for a short time and counting its events per namespace, most active first. Use it as a starting point for " +
+			"cluster-wide triage before running gadgets in specific namespaces."),
+		mcp.WithString("image",
+			mcp.Description(fmt.Sprintf("Gadget image to observe the activity with, it must have a %s field (default %s)",
+				namespaceField, defaultActivityImage)),
+		),
+		mcp.WithNumber("timeout",
+			mcp.Description(fmt.Sprintf("Duration in seconds to observe the activity (default %d, at most %d)",
+				int(defaultActivityTimeout.Seconds()), int(maxActivityTimeout.Seconds()))),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+	}
+	tool := mcp.NewTool(
+		"namespace-activity",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.namespaceActivityHandler,
+	}
+}
+
+func (r *GadgetToolRegistry) namespaceActivityHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if r.environment() != deployer.KubernetesEnv {
+		return mcp.NewToolResultError("namespace-activity is only supported in the Kubernetes environment"), nil
+	}
+	image := request.GetString("image", defaultActivityImage)
+	timeout := defaultActivityTimeout
+	if t := request.GetInt("timeout", 0); t > 0 {
+		timeout = min(time.Duration(t)*time.Second, maxActivityTimeout)
+	}
+
+	info, err := r.gadgetMgr.GetInfo(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("getting gadget info for %s: %w", image, err)
+	}
+	if err := validateAggregate(info, namespaceField); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("gadget %s can't be used to observe namespaces: %s", image, err)), nil
+	}
+	params := defaultParamsFromGadgetInfo(info)
+	if _, ok := params[namespaceParam]; ok {
+		params[namespaceParam] = ""
+	}
+	if _, ok := params[allNamespacesParam]; ok {
+		params[allNamespacesParam] = "true"
+	}
+	r.applyGlobalFilter(ctx, info, params)
+	runParams, err := r.expandParamEnv(params)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	release, err := r.acquireRun(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	resp, err := r.gadgetMgr.Run(info.ImageName, runParams, timeout, nil)
+	release()
+	if err != nil {
+		r.recordRunError(info.ImageName, params, nil, false, err)
+		return nil, fmt.Errorf("running gadget %s: %w", info.ImageName, err)
+	}
+
+	activity, err := namespaceActivityFromResult(resp)
+	if err != nil {
+		return nil, err
+	}
+	activity.Gadget = info.ImageName
+	activity.Window = timeout.String()
+	out, err := json.Marshal(activity)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling namespace activity: %w", err)
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// namespaceActivityFromResult counts the events of a result per namespace, most active first.
+func namespaceActivityFromResult(result *gadgetmanager.RunResult) (*namespaceActivity, error) {
+	aggregated, err := aggregateResults(result, namespaceField)
+	if err != nil {
+		return nil, fmt.Errorf("counting events per namespace: %w", err)
+	}
+	activity := &namespaceActivity{Events: result.Events, Namespaces: []namespaceActivityEntry{}}
+	for _, line := range gadgetmanager.EventLines(aggregated.Output) {
+		var group aggregateGroup
+		if err := json.Unmarshal([]byte(line), &group); err != nil {
+			return nil, fmt.Errorf("decoding aggregate group: %w", err)
+		}
+		if group.Value == missingValue || group.Value == "" {
+			activity.HostEvents += group.Count
+			continue
+		}
+		activity.Namespaces = append(activity.Namespaces, namespaceActivityEntry{Namespace: group.Value, Events: group.Count})
+	}
+	return activity, nil
+}
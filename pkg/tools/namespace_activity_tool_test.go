@@ -0,0 +1,82 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestNamespaceActivity(t *testing.T) {
+	result := &gadgetmanager.RunResult{
+		Output: `{"k8s":{"namespace":"default"}}
+{"k8s":{"namespace":"kube-system"}}
+{"k8s":{"namespace":"default"}}
+{"k8s":{"namespace":""}}
+{"comm":"sshd"}
+`,
+		Events: 5,
+	}
+	activity, err := namespaceActivityFromResult(result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []namespaceActivityEntry{{Namespace: "default", Events: 2}, {Namespace: "kube-system", Events: 1}}
+	if !slices.Equal(activity.Namespaces, expected) || activity.HostEvents != 2 || activity.Events != 5 {
+		t.Errorf("unexpected activity %+v", activity)
+	}
+
+	r, _ := newTestRegistry(t)
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"image": "trace_dns:latest"}
+	if res, err := r.namespaceActivityHandler(context.Background(), request); err != nil || !res.IsError {
+		t.Errorf("expected a tool error for a gadget without %s, got %v, %v", namespaceField, res, err)
+	}
+}
+
+func TestNamespaceActivityRun(t *testing.T) {
+	t.Setenv("IG_MCP_TEST_TOKEN", "secret")
+	r, mgr := newTestRegistry(t, WithParamEnv([]string{"IG_MCP_TEST_TOKEN"}))
+	mgr.Infos["trace_exec:latest"] = &api.GadgetInfo{
+		ImageName: "trace_exec:latest",
+		Params: []*api.Param{
+			{Prefix: "operator.oci.", Key: "token", DefaultValue: "${IG_MCP_TEST_TOKEN}"},
+		},
+		DataSources: []*api.DataSource{
+			{Name: "exec", Fields: []*api.Field{{FullName: namespaceField}}},
+		},
+	}
+	mgr.Result = &gadgetmanager.RunResult{Output: "{\"k8s\":{\"namespace\":\"default\"}}\n", Events: 1}
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"timeout": float64(3600)}
+	res, err := r.namespaceActivityHandler(context.Background(), request)
+	if err != nil || res.IsError {
+		t.Fatalf("unexpected error: %v, %v", res, err)
+	}
+	calls := mgr.RunCalls()
+	if len(calls) != 1 || calls[0].Timeout != maxActivityTimeout {
+		t.Fatalf("expected a single run clamped to %s, got %+v", maxActivityTimeout, calls)
+	}
+	if got := calls[0].Params["operator.oci.token"]; got != "secret" {
+		t.Errorf("expected the param to be expanded, got %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const namespaceParam = "operator.KubeManager.namespace"
+
+func (r *GadgetToolRegistry) newSetDefaultNamespaceTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Sets the namespace gadgets run in when the " + namespaceParam + " param isn't given. " +
+			"Use it to avoid passing the namespace on every gadget call during an investigation."),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace to use by default"),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"set-default-namespace",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.setDefaultNamespaceHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) setDefaultNamespaceHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ns := request.GetString("namespace", "")
+		if ns == "" {
+			return nil, fmt.Errorf("a namespace is required")
+		}
+		r.setDefaultNamespace(ns)
+		return mcp.NewToolResultText(fmt.Sprintf("Gadgets will run in namespace %q unless another one is given", ns)), nil
+	}
+}
+
+func (r *GadgetToolRegistry) newClearDefaultNamespaceTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Clears the default namespace set with set-default-namespace, gadgets will use their own default again."),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithIdempotentHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"clear-default-namespace",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.clearDefaultNamespaceHandler(),
+	}
+}
+
+func (r *GadgetToolRegistry) clearDefaultNamespaceHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		r.setDefaultNamespace("")
+		return mcp.NewToolResultText("The default namespace has been cleared"), nil
+	}
+}
+
+func (r *GadgetToolRegistry) setDefaultNamespace(ns string) {
+	r.nsMu.Lock()
+	defer r.nsMu.Unlock()
+	r.defaultNamespace = ns
+}
+
+func (r *GadgetToolRegistry) getDefaultNamespace() string {
+	r.nsMu.RLock()
+	defer r.nsMu.RUnlock()
+	return r.defaultNamespace
+}
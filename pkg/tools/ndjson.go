@@ -0,0 +1,69 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+const ndjsonMIMEType = "application/x-ndjson"
+
+// ndjsonResult returns the metadata of a result as text and its events as an embedded application/x-ndjson resource.
+// Events that would make the resource longer than the result limit are left out as a whole, so that it stays valid
+// NDJSON.
+func (r *GadgetToolRegistry) ndjsonResult(result *gadgetmanager.RunResult) *mcp.CallToolResult {
+	md := newResultMetadata(result)
+	var events string
+	events, md.Truncated = truncateEventLines(result.Output, r.resultLen())
+
+	// Marshalling a struct of basic types can't fail
+	mdJson, _ := json.Marshal(md)
+	text := fmt.Sprintf("\n<metadata>%s</metadata>\n", mdJson)
+	if len(result.Messages) > 0 {
+		text += fmt.Sprintf("<messages>\n%s\n</messages>\n", strings.Join(result.Messages, "\n"))
+	}
+	// Stored so that the URI can be read again with the gadget-results resource template
+	uri := resultsURI(r.storedResults.put(events))
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.NewTextContent(text),
+			mcp.NewEmbeddedResource(mcp.TextResourceContents{
+				URI:      uri,
+				MIMEType: ndjsonMIMEType,
+				Text:     events,
+			}),
+		},
+	}
+}
+
+// truncateEventLines returns the events of output that fit in maxLen, leaving out the ones that don't as a whole so
+// that it stays valid NDJSON, and whether some were left out.
+func truncateEventLines(output string, maxLen int) (string, bool) {
+	var b strings.Builder
+	for _, line := range gadgetmanager.EventLines(output) {
+		if b.Len()+len(line)+1 > maxLen {
+			return b.String(), true
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String(), false
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestNDJSONFormat(t *testing.T) {
+	r, _ := newTestRegistry(t)
+
+	res, err := callTool(t, r, map[string]any{"format": "ndjson"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Content) != 2 {
+		t.Fatalf("expected the metadata and a resource, got %v", res.Content)
+	}
+	if text := res.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "<metadata>") || strings.Contains(text, "<results>") {
+		t.Errorf("expected only the metadata as text, got %s", text)
+	}
+	resource, ok := res.Content[1].(mcp.EmbeddedResource)
+	if !ok {
+		t.Fatalf("expected an embedded resource, got %T", res.Content[1])
+	}
+	contents := resource.Resource.(mcp.TextResourceContents)
+	if contents.MIMEType != "application/x-ndjson" || !strings.HasPrefix(contents.URI, resultsURIPrefix) {
+		t.Errorf("unexpected resource %q with MIME type %q", contents.URI, contents.MIMEType)
+	}
+	if contents.Text != "{\"name\":\"example.com.\",\"qr\":\"Q\"}\n" {
+		t.Errorf("unexpected resource contents %q", contents.Text)
+	}
+
+	// The URI of the embedded resource can be read with the results template
+	_, handler := r.ResultsResourceTemplate()
+	var request mcp.ReadResourceRequest
+	request.Params.URI = contents.URI
+	read, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("reading %s: %v", contents.URI, err)
+	}
+	if text := read[0].(mcp.TextResourceContents).Text; text != contents.Text {
+		t.Errorf("expected the stored results, got %q", text)
+	}
+
+	if res, err := callTool(t, r, map[string]any{"format": "ndjson", "background": true}); err != nil || !res.IsError {
+		t.Errorf("expected a tool error for a background run, got %v, %v", res, err)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
+)
+
+// archLabel is the well-known label with the architecture of a Kubernetes node
+const archLabel = "kubernetes.io/arch"
+
+// knownArches are the architectures gadget images are published for
+var knownArches = []string{"amd64", "arm64"}
+
+// parseNodes splits a comma-separated list of nodes, ignoring empty entries.
+func parseNodes(s string) []string {
+	var nodes []string
+	for _, n := range strings.Split(s, ",") {
+		if n = strings.TrimSpace(n); n != "" && !slices.Contains(nodes, n) {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// validateNodes checks that all given nodes exist in the cluster.
+func validateNodes(ctx context.Context, nodes []string) error {
+	client, err := newKubernetesClient()
+	if err != nil {
+		return err
+	}
+	list, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing nodes: %w", err)
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, n := range list.Items {
+		names = append(names, n.Name)
+	}
+	for _, n := range nodes {
+		if !slices.Contains(names, n) {
+			return fmt.Errorf("unknown node %q, valid nodes are: %s", n, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// archNodes returns the nodes with the given architecture, only among the given nodes if any. Inspektor Gadget pulls
+// the gadget image for the architecture of each node it runs on, so the architecture of a run is pinned by running it
+// only on the nodes of that architecture.
+func archNodes(ctx context.Context, client kubernetes.Interface, arch string, nodes []string) ([]string, error) {
+	list, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: archLabel + "=" + arch})
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+	var res []string
+	for _, n := range list.Items {
+		if len(nodes) == 0 || slices.Contains(nodes, n.Name) {
+			res = append(res, n.Name)
+		}
+	}
+	if len(res) == 0 {
+		if len(nodes) > 0 {
+			return nil, fmt.Errorf("none of the nodes %s has the %s architecture", strings.Join(nodes, ", "), arch)
+		}
+		return nil, fmt.Errorf("no node has the %s architecture", arch)
+	}
+	slices.Sort(res)
+	return res, nil
+}
+
+// pinArch returns the nodes to run a gadget on for it to use the image for arch, see archNodes. The ig daemon of the
+// Linux environment is reached through a unix socket, so it runs on this host and only its architecture can be used.
+func (r *GadgetToolRegistry) pinArch(ctx context.Context, arch string, nodes []string) ([]string, error) {
+	if r.environment() == deployer.LinuxEnv {
+		if arch != runtime.GOARCH {
+			return nil, fmt.Errorf("the ig daemon runs on %s, the %s architecture can't be used", runtime.GOARCH, arch)
+		}
+		return nodes, nil
+	}
+	client, err := newKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+	return archNodes(ctx, client, arch, nodes)
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestArchNodes(t *testing.T) {
+	client := fake.NewClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{archLabel: "amd64"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2", Labels: map[string]string{archLabel: "arm64"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-3", Labels: map[string]string{archLabel: "arm64"}}},
+	)
+
+	tests := []struct {
+		arch     string
+		nodes    []string
+		expected []string
+		wantErr  bool
+	}{
+		{arch: "arm64", expected: []string{"node-2", "node-3"}},
+		{arch: "arm64", nodes: []string{"node-1", "node-3"}, expected: []string{"node-3"}},
+		{arch: "amd64", nodes: []string{"node-2"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		nodes, err := archNodes(context.Background(), client, tt.arch, tt.nodes)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expected an error when none of %v is %s, got %v", tt.nodes, tt.arch, nodes)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(nodes, tt.expected) {
+			t.Errorf("expected the %s nodes %v of %v, got %v, %v", tt.arch, tt.expected, tt.nodes, nodes, err)
+		}
+	}
+
+	r, _ := newTestRegistry(t)
+	if res, err := callTool(t, r, map[string]any{"arch": "mips"}); err != nil || !res.IsError {
+		t.Errorf("expected a tool error for an unknown arch, got %v, %v", res, err)
+	}
+}
+
+func TestNodeArgumentLinux(t *testing.T) {
+	r, mgr := newTestRegistry(t, WithEnvironmentInfo(EnvironmentInfo{Runtime: gadgetmanager.RuntimeGrpcLinux}))
+	tool, err := r.toolFromGadgetInfo(testGadgetInfo())
+	if err != nil {
+		t.Fatalf("creating tool: %v", err)
+	}
+	if _, ok := tool.InputSchema.Properties["node"]; ok {
+		t.Error("expected no node argument on Linux")
+	}
+	if _, ok := r.lazyTool("trace_dns:latest", "trace_dns").Tool.InputSchema.Properties["node"]; ok {
+		t.Error("expected no node argument for the lazy tool on Linux")
+	}
+	res, err := callTool(t, r, map[string]any{"node": "node-1"})
+	if err != nil || !res.IsError || len(mgr.RunCalls()) != 0 {
+		t.Errorf("expected node to be rejected on Linux without running the gadget, got %v, %v", res, err)
+	}
+}
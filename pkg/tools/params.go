@@ -0,0 +1,174 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+)
+
+const (
+	// maxParams bounds the number of params accepted in a gadget tool call, gadgets have a few dozens at most
+	maxParams = 256
+	// maxParamsBytes bounds the total size of the keys and values of the params accepted in a gadget tool call
+	maxParamsBytes = 64 * 1024
+)
+
+// WithNestedParams makes the params argument of gadget tools an object grouping the params by their operator prefix,
+// e.g. {"operator.KubeManager": {"namespace": "default"}} instead of {"operator.KubeManager.namespace": "default"}.
+func WithNestedParams(nested bool) Option {
+	return func(r *GadgetToolRegistry) {
+		r.nestedParams = nested
+	}
+}
+
+// WithParamEnv allows gadget param values to reference the given environment variables as ${NAME}, e.g. to pass
+// credentials without giving them to the assistant. References to other variables are rejected.
+func WithParamEnv(names []string) Option {
+	return func(r *GadgetToolRegistry) {
+		r.paramEnv = names
+	}
+}
+
+// envRefPattern matches the environment variable references in param values
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandParamEnv returns a copy of params with the references to allowed environment variables replaced by their
+// values. Params are returned as is if no environment variables are allowed. The expanded values must only be passed
+// to the gadget manager, not logged or returned, as they can be sensitive.
+func (r *GadgetToolRegistry) expandParamEnv(params map[string]string) (map[string]string, error) {
+	if len(r.paramEnv) == 0 {
+		return params, nil
+	}
+	expanded := maps.Clone(params)
+	for k, v := range params {
+		var err error
+		expanded[k] = envRefPattern.ReplaceAllStringFunc(v, func(ref string) string {
+			name := envRefPattern.FindStringSubmatch(ref)[1]
+			if !slices.Contains(r.paramEnv, name) {
+				if err == nil {
+					err = fmt.Errorf("param %q references the environment variable %q, which isn't allowed. Allowed ones are: %s",
+						k, name, strings.Join(r.paramEnv, ", "))
+				}
+				return ref
+			}
+			value, ok := os.LookupEnv(name)
+			if !ok && err == nil {
+				err = fmt.Errorf("param %q references the environment variable %q, which isn't set", k, name)
+			}
+			return value
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return expanded, nil
+}
+
+// paramsSchema returns the JSON schema properties of the params argument of a gadget tool.
+func paramsSchema(info *api.GadgetInfo, nested bool) map[string]interface{} {
+	params := make(map[string]interface{})
+	for _, p := range info.Params {
+		prop := map[string]interface{}{
+			"type":        "string",
+			"description": p.Description,
+		}
+		group := strings.TrimSuffix(p.Prefix, ".")
+		if !nested || group == "" {
+			params[p.Prefix+p.Key] = prop
+			continue
+		}
+		g, ok := params[group].(map[string]interface{})
+		if !ok {
+			g = map[string]interface{}{
+				"type":       "object",
+				"properties": make(map[string]interface{}),
+			}
+			params[group] = g
+		}
+		g["properties"].(map[string]interface{})[p.Key] = prop
+	}
+	return params
+}
+
+// flattenParams converts the params argument of a gadget tool to the prefixed keys used by the runtime. With nested
+// set, objects are flattened by joining their key with the keys of their values.
+func flattenParams(p map[string]interface{}, nested bool) (map[string]string, error) {
+	if len(p) > maxParams {
+		return nil, fmt.Errorf("too many parameters: %d, at most %d are accepted", len(p), maxParams)
+	}
+	params := make(map[string]string, len(p))
+	for k, v := range p {
+		switch val := v.(type) {
+		case string:
+			params[k] = val
+		case map[string]interface{}:
+			if !nested {
+				return nil, fmt.Errorf("invalid type for parameter %s: expected string, got %T", k, v)
+			}
+			for gk, gv := range val {
+				strVal, ok := gv.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid type for parameter %s.%s: expected string, got %T", k, gk, gv)
+				}
+				params[k+"."+gk] = strVal
+			}
+		default:
+			return nil, fmt.Errorf("invalid type for parameter %s: expected string, got %T", k, v)
+		}
+	}
+	if len(params) > maxParams {
+		return nil, fmt.Errorf("too many parameters: %d, at most %d are accepted", len(params), maxParams)
+	}
+	size := 0
+	for k, v := range params {
+		size += len(k) + len(v)
+	}
+	if size > maxParamsBytes {
+		return nil, fmt.Errorf("parameters too large: %d bytes, at most %d are accepted", size, maxParamsBytes)
+	}
+	return params, nil
+}
+
+// boolArg returns a boolean argument, false if it's missing. Some clients send booleans as strings or numbers, so
+// "true", "1" and 1 are accepted too.
+func boolArg(args map[string]any, name string) (bool, error) {
+	switch v := args[name].(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("invalid value %q for %s, expected true or false", v, name)
+		}
+		return b, nil
+	case float64:
+		if v != 0 && v != 1 {
+			return false, fmt.Errorf("invalid value %v for %s, expected true or false", v, name)
+		}
+		return v == 1, nil
+	default:
+		return false, fmt.Errorf("invalid type %T for %s, expected a boolean", v, name)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestNestedParamsRoundTrip(t *testing.T) {
+	info := testGadgetInfo()
+	schema := paramsSchema(info, true)
+
+	// Fill every param of the nested schema with its flat key, flattening must give back the same keys
+	args := make(map[string]interface{})
+	for group, prop := range schema {
+		props, ok := prop.(map[string]interface{})["properties"].(map[string]interface{})
+		if !ok {
+			args[group] = group
+			continue
+		}
+		values := make(map[string]interface{})
+		for key := range props {
+			values[key] = group + "." + key
+		}
+		args[group] = values
+	}
+
+	flat, err := flattenParams(args, true)
+	if err != nil {
+		t.Fatalf("flattening params: %v", err)
+	}
+	expected := make(map[string]string)
+	for k := range defaultParamsFromGadgetInfo(info) {
+		expected[k] = k
+	}
+	if !maps.Equal(flat, expected) {
+		t.Errorf("expected %v, got %v", expected, flat)
+	}
+}
+
+func TestFlattenParams(t *testing.T) {
+	nested := map[string]interface{}{
+		"operator.KubeManager": map[string]interface{}{"namespace": "default"},
+	}
+	if _, err := flattenParams(nested, false); err == nil {
+		t.Errorf("expected nested params to be rejected when not enabled")
+	}
+	if _, err := flattenParams(map[string]interface{}{"operator.KubeManager": map[string]interface{}{"namespace": 1.0}}, true); err == nil {
+		t.Errorf("expected an error for a non-string nested param")
+	}
+
+	flat, err := flattenParams(map[string]interface{}{namespaceParam: "default"}, true)
+	if err != nil {
+		t.Fatalf("flattening flat params: %v", err)
+	}
+	if flat[namespaceParam] != "default" {
+		t.Errorf("expected flat params to be kept, got %v", flat)
+	}
+}
+
+func TestHandlerNestedParams(t *testing.T) {
+	r, mgr := newTestRegistry(t, WithNestedParams(true))
+	_, err := callTool(t, r, map[string]any{
+		"params": map[string]any{
+			"operator.KubeManager": map[string]any{"namespace": "kube-system"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("calling tool: %v", err)
+	}
+	if got := mgr.RunCalls()[0].Params[namespaceParam]; got != "kube-system" {
+		t.Errorf("expected namespace %q, got %q", "kube-system", got)
+	}
+}
+
+func TestFlattenParamsLimits(t *testing.T) {
+	many := make(map[string]interface{})
+	for i := 0; i <= maxParams; i++ {
+		many[fmt.Sprintf("param%d", i)] = "value"
+	}
+	if _, err := flattenParams(many, false); err == nil || !strings.Contains(err.Error(), "too many parameters") {
+		t.Errorf("expected too many parameters to be rejected, got %v", err)
+	}
+
+	// A single group can hold more params than allowed once flattened
+	group := make(map[string]interface{})
+	for i := 0; i <= maxParams; i++ {
+		group[fmt.Sprintf("param%d", i)] = "value"
+	}
+	if _, err := flattenParams(map[string]interface{}{"operator.test": group}, true); err == nil {
+		t.Errorf("expected too many nested parameters to be rejected")
+	}
+
+	large := map[string]interface{}{namespaceParam: strings.Repeat("a", maxParamsBytes)}
+	if _, err := flattenParams(large, false); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("expected too large parameters to be rejected, got %v", err)
+	}
+}
+
+func TestParamEnv(t *testing.T) {
+	t.Setenv("IG_MCP_TEST_TOKEN", "secret")
+	t.Setenv("IG_MCP_TEST_OTHER", "other")
+	r, mgr := newTestRegistry(t, WithParamEnv([]string{"IG_MCP_TEST_TOKEN", "IG_MCP_TEST_UNSET"}))
+
+	if _, err := callTool(t, r, map[string]any{"params": map[string]any{"key": "token=${IG_MCP_TEST_TOKEN},re=a$"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mgr.RunCalls()[0].Params["key"]; got != "token=secret,re=a$" {
+		t.Errorf("expected the allowed variable to be expanded, got %q", got)
+	}
+
+	for _, ref := range []string{"${IG_MCP_TEST_OTHER}", "${IG_MCP_TEST_UNSET}"} {
+		res, err := callTool(t, r, map[string]any{"params": map[string]any{"key": ref}})
+		if err != nil || !res.IsError {
+			t.Errorf("expected a tool error for %s, got %v, %v", ref, res, err)
+		}
+	}
+	if len(mgr.RunCalls()) != 1 {
+		t.Errorf("expected the gadget not to run with invalid references")
+	}
+
+	// References are kept as is if no variables are allowed
+	r, mgr = newTestRegistry(t)
+	if _, err := callTool(t, r, map[string]any{"params": map[string]any{"key": "${IG_MCP_TEST_TOKEN}"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := mgr.RunCalls()[0].Params["key"]; got != "${IG_MCP_TEST_TOKEN}" {
+		t.Errorf("expected the reference to be kept, got %q", got)
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+// Labels set on a resumed gadget instance to record the gap in its data
+const (
+	resumedFromLabel = "resumed-from"
+	pausedAtLabel    = "paused-at"
+)
+
+// maxBackgroundRuns is the number of tracked background runs above which the ones that aren't running anymore, e.g.
+// because they ended on their own or failed, are dropped
+const maxBackgroundRuns = 100
+
+// backgroundRun holds what is needed to start a background gadget run again. Inspektor Gadget can't pause a gadget
+// instance, so pausing stops it and resuming starts a new instance with the same parameters.
+type backgroundRun struct {
+	image  string
+	params map[string]string
+	nodes  []string
+	labels map[string]string
+	// stream tells if the events of the run were sent as notifications, see WithStreaming
+	stream   bool
+	started  time.Time
+	pausedAt time.Time
+}
+
+// trackBackgroundRun remembers the parameters of a background run so it can be paused and resumed. params must not be
+// expanded with expandParamEnv, as they are reported by last-error if resuming fails.
+func (r *GadgetToolRegistry) trackBackgroundRun(ctx context.Context, id, image string, params map[string]string, nodes []string,
+	labels map[string]string, stream bool) {
+	r.pruneBackgroundRuns(ctx)
+
+	r.runsMu.Lock()
+	defer r.runsMu.Unlock()
+	if r.backgroundRuns == nil {
+		r.backgroundRuns = make(map[string]backgroundRun)
+	}
+	r.backgroundRuns[id] = backgroundRun{
+		image:   image,
+		params:  maps.Clone(params),
+		nodes:   slices.Clone(nodes),
+		labels:  maps.Clone(labels),
+		stream:  stream,
+		started: time.Now(),
+	}
+}
+
+// pruneBackgroundRuns drops the tracked background runs that the gadget manager doesn't list anymore, once there are
+// maxBackgroundRuns of them. Only the runs tracked before listing are considered, as the others may not be listed yet.
+func (r *GadgetToolRegistry) pruneBackgroundRuns(ctx context.Context) {
+	r.runsMu.Lock()
+	n := len(r.backgroundRuns)
+	r.runsMu.Unlock()
+	if n < maxBackgroundRuns {
+		return
+	}
+
+	listed := time.Now()
+	instances, err := r.gadgetMgr.ListInstances(ctx)
+	if err != nil {
+		log.DebugContext(ctx, "Failed to list gadget instances to prune the background runs", "error", err)
+		return
+	}
+	running := make(map[string]bool, len(instances))
+	for _, inst := range instances {
+		running[inst.ID] = true
+	}
+	r.runsMu.Lock()
+	defer r.runsMu.Unlock()
+	for id, run := range r.backgroundRuns {
+		if !running[id] && run.started.Before(listed) {
+			delete(r.backgroundRuns, id)
+		}
+	}
+}
+
+// forgetBackgroundRun drops the parameters of a stopped background run.
+func (r *GadgetToolRegistry) forgetBackgroundRun(id string) {
+	r.runsMu.Lock()
+	defer r.runsMu.Unlock()
+	delete(r.backgroundRuns, id)
+	delete(r.pausedRuns, id)
+}
+
+func (r *GadgetToolRegistry) newPauseTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Pauses a gadget running in the background with an ID. The gadget is stopped and its parameters " +
+			"are kept so that it can be resumed with resume-gadget, no events are collected while it's paused."),
+		mcp.WithString("id",
+			mcp.Description("ID of the running gadget"),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+	}
+	tool := mcp.NewTool(
+		"pause-gadget",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.pauseHandler,
+	}
+}
+
+func (r *GadgetToolRegistry) pauseHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id := request.GetString("id", "")
+	if id == "" {
+		return mcp.NewToolResultError("an id is required"), nil
+	}
+
+	r.runsMu.Lock()
+	run, ok := r.backgroundRuns[id]
+	_, paused := r.pausedRuns[id]
+	if ok {
+		// Claim the run so that concurrent calls can't pause it twice
+		delete(r.backgroundRuns, id)
+	}
+	r.runsMu.Unlock()
+	if !ok {
+		if paused {
+			return mcp.NewToolResultError(fmt.Sprintf("gadget with ID %q is already paused", id)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("gadget with ID %q wasn't started in the background by this server", id)), nil
+	}
+
+	if err := r.gadgetMgr.Stop(id); err != nil {
+		r.runsMu.Lock()
+		r.backgroundRuns[id] = run
+		r.runsMu.Unlock()
+		return nil, fmt.Errorf("failed to pause gadget with id %q: %w", id, err)
+	}
+
+	run.pausedAt = time.Now()
+	r.runsMu.Lock()
+	if r.pausedRuns == nil {
+		r.pausedRuns = make(map[string]backgroundRun)
+	}
+	r.pausedRuns[id] = run
+	r.runsMu.Unlock()
+	log.InfoContext(ctx, "Gadget paused", "id", id, "image", run.image)
+	return mcp.NewToolResultText(fmt.Sprintf("Gadget with ID %q has been paused. Its collected events are discarded, "+
+		"use resume-gadget to start collecting again.", id)), nil
+}
+
+func (r *GadgetToolRegistry) newResumeTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Resumes a gadget paused with pause-gadget. The gadget is started again with the same parameters " +
+			"under a new ID, the events of the paused period are missing and the new instance is labeled with " +
+			resumedFromLabel + " and " + pausedAtLabel + " to record the gap."),
+		mcp.WithString("id",
+			mcp.Description("ID of the paused gadget"),
+			mcp.Required(),
+		),
+		mcp.WithReadOnlyHintAnnotation(false),
+	}
+	tool := mcp.NewTool(
+		"resume-gadget",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.resumeHandler,
+	}
+}
+
+func (r *GadgetToolRegistry) resumeHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id := request.GetString("id", "")
+	if id == "" {
+		return mcp.NewToolResultError("an id is required"), nil
+	}
+
+	r.runsMu.Lock()
+	run, ok := r.pausedRuns[id]
+	if ok {
+		// Claim the paused run so that concurrent calls can't resume it twice
+		delete(r.pausedRuns, id)
+	}
+	r.runsMu.Unlock()
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("gadget with ID %q isn't paused", id)), nil
+	}
+
+	// The params are kept unexpanded, the environment is read again when resuming
+	runParams, err := r.expandParamEnv(run.params)
+	if err != nil {
+		r.runsMu.Lock()
+		r.pausedRuns[id] = run
+		r.runsMu.Unlock()
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	runOpts := []gadgetmanager.RunOption{gadgetmanager.WithContext(ctx)}
+	// The events are streamed to the session resuming the gadget, if it can receive them
+	var events *eventStream
+	if run.stream && r.canStream() {
+		if srv := server.ServerFromContext(ctx); srv != nil {
+			events = newEventStream(ctx, srv.SendNotificationToClient, r.resultLen())
+			runOpts = append(runOpts, events.runOption())
+		}
+	}
+	newID, err := r.gadgetMgr.RunDetached(run.image, runParams, run.nodes, runOpts...)
+	if err != nil {
+		if events != nil {
+			events.cancel()
+		}
+		r.runsMu.Lock()
+		r.pausedRuns[id] = run
+		r.runsMu.Unlock()
+		r.recordRunError(run.image, run.params, run.nodes, true, err)
+		return nil, fmt.Errorf("resuming gadget with id %q: %w", id, err)
+	}
+
+	labels := maps.Clone(run.labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[resumedFromLabel] = id
+	labels[pausedAtLabel] = run.pausedAt.UTC().Format(time.RFC3339)
+	if err := r.gadgetMgr.SetInstanceLabels(newID, labels); err != nil {
+		log.WarnContext(ctx, "Failed to set gadget instance labels", "id", newID, "error", err)
+	}
+	r.trackBackgroundRun(ctx, newID, run.image, run.params, run.nodes, run.labels, events != nil)
+	log.InfoContext(ctx, "Gadget resumed", "id", id, "newID", newID, "image", run.image)
+	msg := fmt.Sprintf("Gadget with ID %q has been resumed with the new ID %s. Events between %s and now weren't collected.",
+		id, newID, labels[pausedAtLabel])
+	switch {
+	case events != nil:
+		go events.run(newID)
+		msg += fmt.Sprintf(" Its events are sent as %s notifications again.", eventsNotification)
+	case run.stream:
+		msg += " Its events were streamed, but can't be streamed to this session, read them with get-results instead."
+	}
+	return mcp.NewToolResultText(msg), nil
+}
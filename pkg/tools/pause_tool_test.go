@@ -0,0 +1,155 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPauseResume(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+
+	res, err := callTool(t, r, map[string]any{"background": true, "labels": map[string]any{"incident": "INC-123"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	instances, err := mgr.ListInstances(context.Background())
+	if err != nil || len(instances) != 1 {
+		t.Fatalf("expected one instance, got %v, %v (%s)", instances, err, resultText(t, res))
+	}
+	id := instances[0].ID
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"id": id}
+	if res, err := r.pauseHandler(context.Background(), request); err != nil || res.IsError {
+		t.Fatalf("pausing: %v, %v", res, err)
+	}
+	if instances, _ := mgr.ListInstances(context.Background()); len(instances) != 0 {
+		t.Errorf("expected the paused instance to be stopped, got %v", instances)
+	}
+	if res, err := r.pauseHandler(context.Background(), request); err != nil || !res.IsError {
+		t.Errorf("expected a tool error pausing twice, got %v, %v", res, err)
+	}
+
+	if res, err := r.resumeHandler(context.Background(), request); err != nil || res.IsError {
+		t.Fatalf("resuming: %v, %v", res, err)
+	}
+	calls := mgr.DetachedCalls()
+	if len(calls) != 2 || calls[1].Image != calls[0].Image || !maps.Equal(calls[1].Params, calls[0].Params) {
+		t.Errorf("expected the gadget to be started again with the same parameters, got %+v", calls)
+	}
+	instances, _ = mgr.ListInstances(context.Background())
+	if len(instances) != 1 || instances[0].ID == id {
+		t.Fatalf("expected a new instance, got %v", instances)
+	}
+	labels := instances[0].Labels
+	if labels["incident"] != "INC-123" || labels[resumedFromLabel] != id || labels[pausedAtLabel] == "" {
+		t.Errorf("expected the original labels and the gap to be recorded, got %v", labels)
+	}
+	if res, err := r.resumeHandler(context.Background(), request); err != nil || !res.IsError {
+		t.Errorf("expected a tool error resuming twice, got %v, %v", res, err)
+	}
+}
+
+func TestPauseStopFailure(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	if _, err := callTool(t, r, map[string]any{"background": true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	instances, err := mgr.ListInstances(context.Background())
+	if err != nil || len(instances) != 1 {
+		t.Fatalf("expected one instance, got %v, %v", instances, err)
+	}
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"id": instances[0].ID}
+	mgr.Err = errors.New("connection refused")
+	if _, err := r.pauseHandler(context.Background(), request); err == nil {
+		t.Fatalf("expected an error when the gadget can't be stopped")
+	}
+	mgr.Err = nil
+	if res, err := r.pauseHandler(context.Background(), request); err != nil || res.IsError {
+		t.Errorf("expected the run to be kept and paused on retry, got %v, %v", res, err)
+	}
+}
+
+func TestResumeParamEnv(t *testing.T) {
+	t.Setenv("IG_MCP_TEST_TOKEN", "secret")
+	r, mgr := newTestRegistry(t, WithParamEnv([]string{"IG_MCP_TEST_TOKEN"}))
+	if _, err := callTool(t, r, map[string]any{"background": true, "params": map[string]any{"key": "${IG_MCP_TEST_TOKEN}"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	instances, err := mgr.ListInstances(context.Background())
+	if err != nil || len(instances) != 1 {
+		t.Fatalf("expected one instance, got %v, %v", instances, err)
+	}
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]any{"id": instances[0].ID}
+	if res, err := r.pauseHandler(context.Background(), request); err != nil || res.IsError {
+		t.Fatalf("pausing: %v, %v", res, err)
+	}
+	mgr.Err = errors.New("connection refused")
+	if _, err := r.resumeHandler(context.Background(), request); err == nil {
+		t.Fatalf("expected resuming to fail")
+	}
+	if calls := mgr.DetachedCalls(); len(calls) != 2 || calls[1].Params["key"] != "secret" {
+		t.Errorf("expected the params to be expanded when resuming, got %+v", calls)
+	}
+
+	request.Params.Arguments = map[string]any{}
+	res, err := r.lastErrorHandler()(context.Background(), request)
+	if err != nil {
+		t.Fatalf("calling last-error: %v", err)
+	}
+	if text := resultText(t, res); strings.Contains(text, "secret") || !strings.Contains(text, "${IG_MCP_TEST_TOKEN}") {
+		t.Errorf("expected the unexpanded params in the last error, got %s", text)
+	}
+}
+
+func TestPruneBackgroundRuns(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	if _, err := callTool(t, r, map[string]any{"background": true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Runs that ended on their own are still tracked until the limit is reached
+	for i := range maxBackgroundRuns {
+		r.backgroundRuns[fmt.Sprintf("ended-%d", i)] = backgroundRun{image: "trace_dns:latest", started: time.Now().Add(-time.Hour)}
+	}
+
+	if _, err := callTool(t, r, map[string]any{"background": true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	instances, err := mgr.ListInstances(context.Background())
+	if err != nil || len(instances) != 2 {
+		t.Fatalf("expected two instances, got %v, %v", instances, err)
+	}
+	if len(r.backgroundRuns) != 2 {
+		t.Errorf("expected only the running instances to be tracked, got %d runs", len(r.backgroundRuns))
+	}
+	for _, inst := range instances {
+		if _, ok := r.backgroundRuns[inst.ID]; !ok {
+			t.Errorf("expected running instance %s to be tracked", inst.ID)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	metadatav1 "github.com/inspektor-gadget/inspektor-gadget/pkg/metadata/v1"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+const (
+	previewStatusAvailable = "available"
+	previewStatusPending   = "pending deployment"
+	previewStatusFailed    = "failed"
+)
+
+type toolPreview struct {
+	Image  string `json:"image"`
+	Tool   string `json:"tool,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (r *GadgetToolRegistry) newPreviewTool(images []string) server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Lists the gadget tools this server provides for the configured gadget images. If Inspektor Gadget " +
+			"isn't deployed, the images are listed with a 'pending deployment' status as their tools are only available after deploying it."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"preview-gadget-tools",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.previewHandler(images),
+	}
+}
+
+func (r *GadgetToolRegistry) previewHandler(images []string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var err error
+		if r.envInfo.Runtime != gadgetmanager.RuntimeGrpcLinux {
+			_, err = r.inspektorGadgetNamespace(ctx)
+		}
+		deployed := err == nil
+		if err != nil && !errors.Is(err, ErrNotDeployed) {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		previews := make([]toolPreview, 0, len(images))
+		for _, img := range images {
+			p := toolPreview{Image: img, Status: previewStatusPending}
+			if deployed {
+				p = r.previewImage(ctx, img)
+			}
+			previews = append(previews, p)
+		}
+		out, err := json.Marshal(previews)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling tool previews: %w", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
+
+func (r *GadgetToolRegistry) previewImage(ctx context.Context, image string) toolPreview {
+	p := toolPreview{Image: image, Status: previewStatusFailed}
+	info, err := r.gadgetMgr.GetInfo(ctx, image)
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	var metadata *metadatav1.GadgetMetadata
+	if err := yaml.Unmarshal(info.Metadata, &metadata); err != nil {
+		p.Error = fmt.Sprintf("unmarshalling gadget metadata: %s", err)
+		return p
+	}
+	if metadata == nil {
+		metadata = &metadatav1.GadgetMetadata{}
+	}
+	p.Tool = toolName(info, metadata)
+	if p.Tool == "" {
+		p.Error = errEmptyToolName.Error()
+		return p
+	}
+	p.Status = previewStatusAvailable
+	return p
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestPreviewGadgetToolsLinux(t *testing.T) {
+	r, _ := newTestRegistry(t, WithEnvironmentInfo(EnvironmentInfo{Runtime: gadgetmanager.RuntimeGrpcLinux}))
+	res, err := r.previewHandler([]string{testGadgetInfo().ImageName})(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("expected preview to not need a Kubernetes deployment on linux, got %s", resultText(t, res))
+	}
+	var previews []toolPreview
+	if err := json.Unmarshal([]byte(resultText(t, res)), &previews); err != nil {
+		t.Fatalf("unmarshalling previews: %v", err)
+	}
+	if len(previews) != 1 || previews[0].Status != previewStatusAvailable {
+		t.Errorf("expected the gadget tool to be available, got %+v", previews)
+	}
+}
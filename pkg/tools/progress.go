@@ -0,0 +1,131 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	progressInterval = 2 * time.Second
+	// maxDeployLogLines is the number of the last deploy log lines returned in the result of the deploy tool
+	maxDeployLogLines = 50
+)
+
+// startProgress reports the number of events collected by a foreground gadget run as MCP progress notifications, if
+// the request asked for them with a progress token. The run must increment the returned counter, see
+// gadgetmanager.WithEventCounter. Notifications are only allowed while the request is in flight, so stop must be
+// called before the handler returns, no notification is sent once it returned.
+func (r *GadgetToolRegistry) startProgress(ctx context.Context, request mcp.CallToolRequest) (counter *atomic.Int64, stop func()) {
+	counter = &atomic.Int64{}
+	meta := request.Params.Meta
+	srv := server.ServerFromContext(ctx)
+	if meta == nil || meta.ProgressToken == nil || srv == nil {
+		return counter, func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		var last int64
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			// Progress must increase with every notification
+			events := counter.Load()
+			if events <= last {
+				continue
+			}
+			last = events
+			err := srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+				"progressToken": meta.ProgressToken,
+				"progress":      events,
+				"message":       fmt.Sprintf("The gadget has collected %d events", events),
+			})
+			if err != nil {
+				log.DebugContext(ctx, "Stopping progress notifications", "error", err)
+				return
+			}
+		}
+	}()
+	return counter, func() {
+		close(done)
+		<-stopped
+	}
+}
+
+type deployProgress struct {
+	ctx   context.Context
+	srv   *server.MCPServer
+	token mcp.ProgressToken
+
+	mu    sync.Mutex
+	count int
+	lines []string
+}
+
+func newDeployProgress(ctx context.Context, request mcp.CallToolRequest) *deployProgress {
+	p := &deployProgress{ctx: ctx}
+	if meta := request.Params.Meta; meta != nil && meta.ProgressToken != nil {
+		p.srv = server.ServerFromContext(ctx)
+		p.token = meta.ProgressToken
+	}
+	return p
+}
+
+// report records msg and sends it to the client.
+func (p *deployProgress) report(msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.count++
+	p.lines = append(p.lines, msg)
+	if len(p.lines) > maxDeployLogLines {
+		p.lines = p.lines[len(p.lines)-maxDeployLogLines:]
+	}
+	if p.srv == nil {
+		return
+	}
+	err := p.srv.SendNotificationToClient(p.ctx, "notifications/progress", map[string]any{
+		"progressToken": p.token,
+		"progress":      p.count,
+		"message":       msg,
+	})
+	if err != nil {
+		log.DebugContext(p.ctx, "Failed to send deploy progress", "error", err)
+	}
+}
+
+// String returns the last reported messages, one per line.
+func (p *deployProgress) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return strings.Join(p.lines, "\n")
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// testSession is an initialized MCP client session collecting the notifications sent to it
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func newTestSession() *testSession {
+	return &testSession{notifications: make(chan mcp.JSONRPCNotification, 16)}
+}
+
+func (s *testSession) Initialize()       {}
+func (s *testSession) Initialized() bool { return true }
+func (s *testSession) SessionID() string { return "test" }
+func (s *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func TestStartProgress(t *testing.T) {
+	r, _ := newTestRegistry(t)
+
+	// Without a progress token, the events are only counted
+	counter, stop := r.startProgress(context.Background(), mcp.CallToolRequest{})
+	counter.Add(1)
+	stop()
+
+	session := newTestSession()
+	srv := server.NewMCPServer("test", "0.0.0")
+	srv.AddTool(mcp.NewTool("run"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		counter, stop := r.startProgress(ctx, request)
+		counter.Add(3)
+		select {
+		case n := <-session.notifications:
+			if n.Method != "notifications/progress" || n.Params.AdditionalFields["progress"] != int64(3) {
+				t.Errorf("unexpected notification %+v", n)
+			}
+		case <-time.After(2*progressInterval + time.Second):
+			t.Error("expected a progress notification")
+		}
+		stop()
+		counter.Add(1)
+		return mcp.NewToolResultText("done"), nil
+	})
+	ctx := srv.WithContext(context.Background(), session)
+	srv.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"run","_meta":{"progressToken":"token"}}}`))
+
+	select {
+	case n := <-session.notifications:
+		t.Errorf("expected no notification once the request returned, got %+v", n)
+	case <-time.After(progressInterval + 500*time.Millisecond):
+	}
+}
+
+func TestDeployProgress(t *testing.T) {
+	p := newDeployProgress(context.Background(), mcp.CallToolRequest{})
+	for i := range maxDeployLogLines + 5 {
+		p.report(fmt.Sprintf("line %d", i))
+	}
+	lines := strings.Split(p.String(), "\n")
+	if len(lines) != maxDeployLogLines {
+		t.Fatalf("expected %d lines, got %d", maxDeployLogLines, len(lines))
+	}
+	if lines[0] != "line 5" {
+		t.Errorf("expected the oldest lines to be dropped, got first line %q", lines[0])
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pullParam is the pull policy param of the OCI operator
+const pullParam = "operator.oci.pull"
+
+// pullPolicies are the values of the pull argument of the gadget tools, in the order they are documented. The policy is
+// only applied to runs, getting the gadget info to build the tools uses the backend default.
+var pullPolicies = []string{"always", "if-not-present", "never"}
+
+// ociPullPolicy maps a pull argument to the value of pullParam.
+func ociPullPolicy(pull string) (string, error) {
+	switch pull {
+	case "always":
+		return "always", nil
+	case "if-not-present":
+		return "missing", nil
+	case "never":
+		return "never", nil
+	}
+	return "", fmt.Errorf("invalid pull policy %q, valid values are: %s", pull, strings.Join(pullPolicies, ", "))
+}
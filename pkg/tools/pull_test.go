@@ -0,0 +1,42 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHandlerPull(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	if _, err := callTool(t, r, map[string]any{"pull": "if-not-present"}); err != nil {
+		t.Fatalf("calling tool: %v", err)
+	}
+	calls := mgr.RunCalls()
+	if len(calls) != 1 || calls[0].Params[pullParam] != "missing" {
+		t.Errorf("expected the run to use pull policy %q, got %v", "missing", calls)
+	}
+
+	res, err := callTool(t, r, map[string]any{"pull": "sometimes"})
+	if err != nil {
+		t.Fatalf("calling tool: %v", err)
+	}
+	if !res.IsError || !strings.Contains(resultText(t, res), "invalid pull policy") {
+		t.Errorf("expected an invalid pull policy error, got %v", res)
+	}
+	if len(mgr.RunCalls()) != 1 {
+		t.Errorf("expected no run with an invalid pull policy")
+	}
+}
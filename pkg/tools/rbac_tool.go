@@ -0,0 +1,188 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
+)
+
+// permissionCheck is a permission needed by the server, checked with a SelfSubjectAccessReview.
+type permissionCheck struct {
+	Verb        string
+	Group       string
+	Resource    string
+	Subresource string
+	// Namespace is empty for cluster-scoped resources or all namespaces
+	Namespace string
+	// NeededFor tells what fails without the permission
+	NeededFor string
+}
+
+func (c permissionCheck) String() string {
+	resource := c.Resource
+	if c.Subresource != "" {
+		resource += "/" + c.Subresource
+	}
+	if c.Group != "" {
+		resource += "." + c.Group
+	}
+	if c.Namespace == "" {
+		return fmt.Sprintf("%s %s", c.Verb, resource)
+	}
+	return fmt.Sprintf("%s %s in namespace %s", c.Verb, resource, c.Namespace)
+}
+
+type permissionResult struct {
+	Permission string `json:"permission"`
+	NeededFor  string `json:"neededFor"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+type rbacReport struct {
+	Namespace string             `json:"namespace"`
+	Allowed   []string           `json:"allowed"`
+	Missing   []permissionResult `json:"missing"`
+}
+
+// permissionChecks returns the permissions needed to detect, deploy and run gadgets with Inspektor Gadget in
+// namespace. Deploying requires the permissions of the Helm release, of which only the main resources are checked.
+func permissionChecks(namespace string) []permissionCheck {
+	return []permissionCheck{
+		{Verb: "list", Resource: "pods", NeededFor: "detecting if and where Inspektor Gadget is deployed"},
+		{Verb: "create", Resource: "pods", Subresource: "portforward", Namespace: namespace, NeededFor: "connecting to the gadget gRPC service to run gadgets"},
+		{Verb: "list", Group: "apps", Resource: "daemonsets", Namespace: namespace, NeededFor: "getting the deployed Inspektor Gadget version"},
+		{Verb: "list", Resource: "secrets", Namespace: namespace, NeededFor: "reading the Helm release of Inspektor Gadget"},
+		{Verb: "create", Resource: "secrets", Namespace: namespace, NeededFor: "storing the Helm release when deploying Inspektor Gadget"},
+		{Verb: "create", Resource: "namespaces", NeededFor: "creating the namespace when deploying Inspektor Gadget"},
+		{Verb: "create", Group: "apps", Resource: "daemonsets", Namespace: namespace, NeededFor: "deploying Inspektor Gadget"},
+		{Verb: "create", Resource: "serviceaccounts", Namespace: namespace, NeededFor: "deploying Inspektor Gadget"},
+		{Verb: "create", Resource: "configmaps", Namespace: namespace, NeededFor: "deploying Inspektor Gadget"},
+		{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterroles", NeededFor: "deploying Inspektor Gadget"},
+		{Verb: "create", Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings", NeededFor: "deploying Inspektor Gadget"},
+		{Verb: "delete", Group: "apps", Resource: "daemonsets", Namespace: namespace, NeededFor: "undeploying Inspektor Gadget"},
+	}
+}
+
+func (r *GadgetToolRegistry) newRBACCheckTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Check if the Kubernetes identity used by this server has the RBAC permissions needed to " +
+			"detect, deploy, undeploy and run Inspektor Gadget, and report the missing ones. Use it before deploying or " +
+			"when operations fail with permission errors."),
+		mcp.WithReadOnlyHintAnnotation(true),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace Inspektor Gadget is or will be deployed in, only set if user explicitly specifies a namespace. "+
+				"Defaults to the namespace it's deployed in"),
+		),
+	}
+	tool := mcp.NewTool(
+		"rbac-check",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.rbacCheckHandler,
+	}
+}
+
+func (r *GadgetToolRegistry) rbacCheckHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if env := r.environment(); env != deployer.KubernetesEnv {
+		return mcp.NewToolResultError(fmt.Sprintf("RBAC permissions only apply to Kubernetes, not to the %s environment", env)), nil
+	}
+	namespace := request.GetString("namespace", "")
+	if namespace == "" {
+		ns, err := r.inspektorGadgetNamespace(ctx)
+		switch {
+		case err == nil:
+			namespace = ns
+		case errors.Is(err, ErrNotDeployed):
+			namespace = defaultNamespace
+		default:
+			// Listing pods may be what is forbidden, which is reported by the checks
+			log.DebugContext(ctx, "Failed to get the Inspektor Gadget namespace", "error", err)
+			namespace = defaultNamespace
+		}
+	}
+
+	client, err := newKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+	report, err := checkPermissions(ctx, client, namespace, permissionChecks(namespace))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	out, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling RBAC report: %w", err)
+	}
+	summary := "All the checked permissions are granted."
+	if len(report.Missing) > 0 {
+		var missing []string
+		for _, m := range report.Missing {
+			missing = append(missing, m.Permission)
+		}
+		summary = fmt.Sprintf("Missing %d permissions: %s. Grant them to the identity used by this server, "+
+			"e.g. with a ClusterRole and ClusterRoleBinding.", len(missing), strings.Join(missing, ", "))
+	}
+	return mcp.NewToolResultText(summary + "\n" + string(out)), nil
+}
+
+// checkPermissions reviews each check with a SelfSubjectAccessReview.
+func checkPermissions(ctx context.Context, client kubernetes.Interface, namespace string, checks []permissionCheck) (*rbacReport, error) {
+	report := &rbacReport{
+		Namespace: namespace,
+		Allowed:   []string{},
+		Missing:   []permissionResult{},
+	}
+	for _, c := range checks {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   c.Namespace,
+					Verb:        c.Verb,
+					Group:       c.Group,
+					Resource:    c.Resource,
+					Subresource: c.Subresource,
+				},
+			},
+		}
+		res, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("reviewing access to %s: %w", c, err)
+		}
+		if res.Status.Allowed {
+			report.Allowed = append(report.Allowed, c.String())
+			continue
+		}
+		report.Missing = append(report.Missing, permissionResult{
+			Permission: c.String(),
+			NeededFor:  c.NeededFor,
+			Reason:     res.Status.Reason,
+		})
+	}
+	return report, nil
+}
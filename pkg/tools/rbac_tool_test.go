@@ -0,0 +1,70 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	client := fake.NewClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		// Deny everything cluster-scoped
+		review.Status.Allowed = attrs.Namespace != "" || attrs.Resource == "pods"
+		if !review.Status.Allowed {
+			review.Status.Reason = "forbidden"
+		}
+		return true, review, nil
+	})
+
+	checks := permissionChecks("gadget")
+	report, err := checkPermissions(context.Background(), client, "gadget", checks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedMissing := []string{
+		"create namespaces",
+		"create clusterroles.rbac.authorization.k8s.io",
+		"create clusterrolebindings.rbac.authorization.k8s.io",
+	}
+	var missing []string
+	for _, m := range report.Missing {
+		missing = append(missing, m.Permission)
+		if m.NeededFor == "" || m.Reason != "forbidden" {
+			t.Errorf("expected missing permission %q to have a purpose and a reason, got %+v", m.Permission, m)
+		}
+	}
+	if !slices.Equal(missing, expectedMissing) {
+		t.Errorf("expected missing permissions %v, got %v", expectedMissing, missing)
+	}
+	if len(report.Allowed) != len(checks)-len(expectedMissing) {
+		t.Errorf("expected %d allowed permissions, got %v", len(checks)-len(expectedMissing), report.Allowed)
+	}
+	if !slices.Contains(report.Allowed, "create pods/portforward in namespace gadget") {
+		t.Errorf("expected port-forward to be allowed, got %v", report.Allowed)
+	}
+	if !slices.Contains(report.Allowed, "list daemonsets.apps in namespace gadget") {
+		t.Errorf("expected listing the daemonsets for the version to be checked, got %v", report.Allowed)
+	}
+}
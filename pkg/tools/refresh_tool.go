@@ -0,0 +1,81 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+// WithRegisterAfterDeploy sets whether the deploy tool registers the gadget tools once Inspektor Gadget is deployed,
+// which is the default. If disabled, they are only registered by calling the refresh-gadgets tool.
+func WithRegisterAfterDeploy(register bool) Option {
+	return func(r *GadgetToolRegistry) {
+		r.skipRegisterAfterDeploy = !register
+	}
+}
+
+func (r *GadgetToolRegistry) newRefreshGadgetsTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Register the gadget tools, e.g. after deploying Inspektor Gadget or when gadget tools are " +
+			"missing. The list of tools is updated on success."),
+		mcp.WithReadOnlyHintAnnotation(false),
+	}
+	tool := mcp.NewTool(
+		"refresh-gadgets",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.refreshGadgetsHandler,
+	}
+}
+
+func (r *GadgetToolRegistry) refreshGadgetsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// The ig daemon of the linux environment is always there, only Kubernetes needs to be deployed first
+	var err error
+	if r.envInfo.Runtime != gadgetmanager.RuntimeGrpcLinux {
+		_, err = r.inspektorGadgetNamespace(ctx)
+	}
+	if errors.Is(err, ErrNotDeployed) {
+		return mcp.NewToolResultError("Inspektor Gadget is not deployed, use deploy_inspektor_gadget to deploy it"), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	r.mu.Lock()
+	images := r.images
+	r.mu.Unlock()
+	if err := r.registerAndNotify(ctx, images); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("registering gadget tools: %s", err)), nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	registered := 0
+	for _, img := range r.images {
+		if _, ok := r.tools[img]; ok {
+			registered++
+		}
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Registered %d of %d gadget tools", registered, len(r.images))), nil
+}
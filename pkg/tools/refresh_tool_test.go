@@ -0,0 +1,48 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestRefreshGadgetsLinux(t *testing.T) {
+	r, _ := newTestRegistry(t, WithEnvironmentInfo(EnvironmentInfo{Runtime: gadgetmanager.RuntimeGrpcLinux}))
+	r.images = []string{testGadgetInfo().ImageName}
+	var notified []server.ServerTool
+	r.RegisterCallback(func(tools ...server.ServerTool) {
+		notified = tools
+	})
+
+	res, err := r.refreshGadgetsHandler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("expected refresh to not need a Kubernetes deployment on linux, got %s", resultText(t, res))
+	}
+	if text := resultText(t, res); text != "Registered 1 of 1 gadget tools" {
+		t.Errorf("unexpected result %q", text)
+	}
+	if _, ok := r.tools[testGadgetInfo().ImageName]; !ok || len(notified) == 0 {
+		t.Errorf("expected the gadget tool to be registered and the callbacks to be invoked")
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func (r *GadgetToolRegistry) newResolveGadgetTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Resolve a gadget image reference, e.g. a tag, to the digest and metadata hash of the gadget " +
+			"it points to. Use it to record exactly which gadget version was run."),
+		mcp.WithString("image",
+			mcp.Required(),
+			mcp.Description("Gadget image e.g. trace_dns:latest"),
+		),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"resolve-gadget",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.resolveGadgetHandler,
+	}
+}
+
+func (r *GadgetToolRegistry) resolveGadgetHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	image := request.GetString("image", "")
+	if image == "" {
+		return nil, fmt.Errorf("an image is required")
+	}
+
+	info, err := r.gadgetMgr.ResolveImage(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("resolving gadget image %s: %w", image, err)
+	}
+	out, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling image info: %w", err)
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
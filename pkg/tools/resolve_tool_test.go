@@ -0,0 +1,61 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestResolveGadget(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	info := testGadgetInfo()
+	info.ExtraInfo = &api.ExtraInfo{Data: map[string]*api.GadgetInspectAddendum{
+		"oci.repository": {Content: []byte("ghcr.io/inspektor-gadget/gadget/trace_dns")},
+		"oci.tag":        {Content: []byte("latest")},
+		"oci.digest":     {Content: []byte("sha256:1234")},
+	}}
+	mgr.Infos[info.ImageName] = info
+
+	res, err := r.resolveGadgetHandler(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]any{"image": "trace_dns:latest"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got gadgetmanager.ImageInfo
+	if err := json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &got); err != nil {
+		t.Fatalf("unmarshalling image info: %v", err)
+	}
+	sum := sha256.Sum256(info.Metadata)
+	expected := gadgetmanager.ImageInfo{
+		Image:          "trace_dns:latest",
+		Repository:     "ghcr.io/inspektor-gadget/gadget/trace_dns",
+		Tag:            "latest",
+		Digest:         "sha256:1234",
+		MetadataDigest: fmt.Sprintf("sha256:%x", sum),
+	}
+	if got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
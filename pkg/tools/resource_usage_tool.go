@@ -0,0 +1,139 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// podMetricsPath is the path of the pod metrics served by metrics-server, k8s.io/metrics isn't used to avoid the
+// dependency for a single request.
+const podMetricsPath = "/apis/metrics.k8s.io/v1beta1/namespaces/%s/pods"
+
+// podMetricsList is the subset of the PodMetricsList of the metrics API used by the gadget-resource-usage tool.
+type podMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Containers []struct {
+			Usage map[string]resource.Quantity `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+type podUsage struct {
+	Pod         string `json:"pod"`
+	CPUMillis   int64  `json:"cpuMillicores"`
+	MemoryBytes int64  `json:"memoryBytes"`
+}
+
+type resourceUsage struct {
+	Namespace   string     `json:"namespace"`
+	Pods        []podUsage `json:"pods"`
+	CPUMillis   int64      `json:"totalCpuMillicores"`
+	MemoryBytes int64      `json:"totalMemoryBytes"`
+}
+
+func (r *GadgetToolRegistry) newResourceUsageTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Get the CPU and memory usage of the Inspektor Gadget pods, per pod and in total, to assess " +
+			"the overhead of running gadgets. Requires metrics-server on the cluster. Only available in the Kubernetes environment."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"gadget-resource-usage",
+		opts...,
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.resourceUsageHandler,
+	}
+}
+
+// resourceUsageHandler reads the usage of the gadget pods from the metrics API, the tool is only registered in the
+// Kubernetes environment.
+func (r *GadgetToolRegistry) resourceUsageHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ns, err := r.inspektorGadgetNamespace(ctx)
+	if errors.Is(err, ErrNotDeployed) {
+		return mcp.NewToolResultError("Inspektor Gadget is not deployed"), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client, err := newKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+	data, err := client.Discovery().RESTClient().Get().
+		AbsPath(fmt.Sprintf(podMetricsPath, ns)).
+		Param("labelSelector", r.podSelector).
+		DoRaw(ctx)
+	if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+		log.Debug("Pod metrics not available", "error", err)
+		return mcp.NewToolResultError("The metrics API isn't available, metrics-server needs to be installed on the cluster to get the resource usage of the gadget pods"), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting pod metrics: %w", err)
+	}
+
+	usage, err := parsePodMetrics(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(usage.Pods) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("no metrics found for the gadget pods in namespace %s yet, try again in a minute", ns)), nil
+	}
+	usage.Namespace = ns
+	out, err := json.Marshal(usage)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling resource usage: %w", err)
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// parsePodMetrics sums the container usage of each pod of a PodMetricsList, and of all the pods.
+func parsePodMetrics(data []byte) (*resourceUsage, error) {
+	var list podMetricsList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("decoding pod metrics: %w", err)
+	}
+	usage := &resourceUsage{Pods: make([]podUsage, 0, len(list.Items))}
+	for _, item := range list.Items {
+		pod := podUsage{Pod: item.Metadata.Name}
+		for _, c := range item.Containers {
+			if cpu, ok := c.Usage["cpu"]; ok {
+				pod.CPUMillis += cpu.MilliValue()
+			}
+			if mem, ok := c.Usage["memory"]; ok {
+				pod.MemoryBytes += mem.Value()
+			}
+		}
+		usage.Pods = append(usage.Pods, pod)
+		usage.CPUMillis += pod.CPUMillis
+		usage.MemoryBytes += pod.MemoryBytes
+	}
+	return usage, nil
+}
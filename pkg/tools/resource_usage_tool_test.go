@@ -0,0 +1,43 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"testing"
+)
+
+func TestParsePodMetrics(t *testing.T) {
+	data := []byte(`{"items":[
+		{"metadata":{"name":"gadget-a"},"containers":[{"usage":{"cpu":"12m","memory":"64Mi"}},{"usage":{"cpu":"3m","memory":"1Mi"}}]},
+		{"metadata":{"name":"gadget-b"},"containers":[{"usage":{"cpu":"250000n","memory":"1024Ki"}}]}
+	]}`)
+	usage, err := parsePodMetrics(data)
+	if err != nil {
+		t.Fatalf("parsing pod metrics: %v", err)
+	}
+	if len(usage.Pods) != 2 {
+		t.Fatalf("expected 2 pods, got %d", len(usage.Pods))
+	}
+	if usage.Pods[0].CPUMillis != 15 || usage.Pods[0].MemoryBytes != 65<<20 {
+		t.Errorf("unexpected usage for gadget-a: %+v", usage.Pods[0])
+	}
+	if usage.CPUMillis != 16 || usage.MemoryBytes != 66<<20 {
+		t.Errorf("unexpected total usage: %d millicores, %d bytes", usage.CPUMillis, usage.MemoryBytes)
+	}
+
+	if _, err := parsePodMetrics([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid pod metrics")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Bounds of the result limit set with the set-result-limit tool
+const (
+	minResultLimit = 1024       // 1kb
+	maxResultLimit = 256 * 1024 // 256kb
+)
+
+// resultLen returns the size results are truncated to, maxResultLen unless overridden with set-result-limit.
+func (r *GadgetToolRegistry) resultLen() int {
+	if n := r.resultLimit.Load(); n > 0 {
+		return int(n)
+	}
+	return maxResultLen
+}
+
+func (r *GadgetToolRegistry) newSetResultLimitTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription(fmt.Sprintf("Set the size in bytes gadget results are truncated to for the next calls, "+
+			"e.g. to get more details when there is enough context left or fewer to save it. The default is %d, "+
+			"the limit must be between %d and %d.", maxResultLen, minResultLimit, maxResultLimit)),
+		mcp.WithReadOnlyHintAnnotation(false),
+		mcp.WithNumber("bytes",
+			mcp.Description("Size in bytes results are truncated to, 0 resets it to the default"),
+			mcp.Required(),
+		),
+	}
+	tool := mcp.NewTool(
+		"set-result-limit",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.setResultLimitHandler,
+	}
+}
+
+func (r *GadgetToolRegistry) setResultLimitHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	n := request.GetInt("bytes", -1)
+	switch {
+	case n == 0:
+		r.resultLimit.Store(0)
+		return mcp.NewToolResultText(fmt.Sprintf("Results are truncated to the default of %d bytes", maxResultLen)), nil
+	case n < minResultLimit || n > maxResultLimit:
+		return mcp.NewToolResultError(fmt.Sprintf("bytes must be between %d and %d, or 0 to reset it", minResultLimit, maxResultLimit)), nil
+	}
+	r.resultLimit.Store(int64(n))
+	log.InfoContext(ctx, "Result limit changed", "bytes", n)
+	return mcp.NewToolResultText(fmt.Sprintf("Results are truncated to %d bytes", n)), nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestSetResultLimit(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	setLimit := func(n int) *mcp.CallToolResult {
+		t.Helper()
+		res, err := r.setResultLimitHandler(context.Background(), mcp.CallToolRequest{
+			Params: mcp.CallToolParams{Arguments: map[string]any{"bytes": float64(n)}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return res
+	}
+	result := &gadgetmanager.RunResult{Output: strings.Repeat("x", maxResultLimit+10), Events: 1}
+
+	for _, n := range []int{minResultLimit - 1, maxResultLimit + 1} {
+		if res := setLimit(n); !res.IsError {
+			t.Errorf("expected an error for a limit of %d", n)
+		}
+	}
+	if r.resultLen() != maxResultLen {
+		t.Errorf("expected the default limit after invalid calls, got %d", r.resultLen())
+	}
+
+	setLimit(2048)
+	if out := r.formatResults(context.Background(), result); len(out) > 2048+256 {
+		t.Errorf("expected results truncated to the new limit, got %d bytes", len(out))
+	}
+	setLimit(0)
+	if r.resultLen() != maxResultLen {
+		t.Errorf("expected the limit to be reset, got %d", r.resultLen())
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+// filterSince drops the events older than since. Events without a timestamp are kept; the returned bool is false
+// if none of the events had one, meaning the gadget doesn't support time-based filtering.
+func filterSince(result *gadgetmanager.RunResult, since time.Time) (*gadgetmanager.RunResult, bool) {
+	filtered := *result
+	var out strings.Builder
+	events := 0
+	hasTimestamps := false
+	for _, line := range gadgetmanager.EventLines(result.Output) {
+		if t, ok := gadgetmanager.EventTime(line); ok {
+			hasTimestamps = true
+			if t.Before(since) {
+				continue
+			}
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+		events++
+	}
+	filtered.Output = out.String()
+	filtered.Events = events
+	return &filtered, hasTimestamps || events == 0
+}
+
+// selectFields keeps only the given fields, by full name e.g. k8s.podName, in the events of a result.
+func selectFields(result *gadgetmanager.RunResult, fields []string) (*gadgetmanager.RunResult, error) {
+	var out strings.Builder
+	for _, line := range gadgetmanager.EventLines(result.Output) {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return nil, fmt.Errorf("decoding event: %w", err)
+		}
+		selected := make(map[string]any)
+		for _, field := range fields {
+			copyField(selected, event, strings.Split(field, "."))
+		}
+		b, err := json.Marshal(selected)
+		if err != nil {
+			return nil, fmt.Errorf("encoding event: %w", err)
+		}
+		out.Write(b)
+		out.WriteByte('\n')
+	}
+	selected := *result
+	selected.Output = out.String()
+	return &selected, nil
+}
+
+// copyField copies the value at path from src to dst, creating the parent objects in dst as needed.
+func copyField(dst, src map[string]any, path []string) {
+	value, ok := src[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		dst[path[0]] = value
+		return
+	}
+	srcChild, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	dstChild, ok := dst[path[0]].(map[string]any)
+	if !ok {
+		dstChild = make(map[string]any)
+		dst[path[0]] = dstChild
+	}
+	copyField(dstChild, srcChild, path[1:])
+}
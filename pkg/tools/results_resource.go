@@ -0,0 +1,116 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	resultsURIPrefix   = "gadget-results://"
+	resultsURITemplate = resultsURIPrefix + "{id}"
+	// maxStoredResults is the number of foreground results kept to be read as resources, the oldest ones are dropped
+	maxStoredResults = 16
+)
+
+// resultsURI returns the URI of the results resource of a gadget instance or of stored foreground results.
+func resultsURI(id string) string {
+	return resultsURIPrefix + id
+}
+
+// resultStore keeps the last foreground results returned as resources, e.g. with the ndjson format, so that their URI
+// can be read like the one of a gadget instance. It is safe for concurrent use.
+type resultStore struct {
+	mu      sync.Mutex
+	ids     []string
+	results map[string]string
+}
+
+// put stores results and returns their ID, dropping the oldest results over maxStoredResults.
+func (s *resultStore) put(results string) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	id := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.results == nil {
+		s.results = make(map[string]string)
+	}
+	s.ids = append(s.ids, id)
+	s.results[id] = results
+	if len(s.ids) > maxStoredResults {
+		delete(s.results, s.ids[0])
+		s.ids = s.ids[1:]
+	}
+	return id
+}
+
+func (s *resultStore) get(id string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	results, ok := s.results[id]
+	return results, ok
+}
+
+// ResultsResourceTemplate returns the resource template exposing the events collected by the gadgets running in the
+// background as gadget-results://<id>, so that clients can fetch them on demand and re-fetch them for updates. The
+// last foreground results returned as resources are readable with the same template.
+func (r *GadgetToolRegistry) ResultsResourceTemplate() (mcp.ResourceTemplate, server.ResourceTemplateHandlerFunc) {
+	template := mcp.NewResourceTemplate(
+		resultsURITemplate,
+		"Gadget results",
+		mcp.WithTemplateDescription("Events collected so far by a gadget running in the background with the given ID, "+
+			"or by a foreground run returned as a resource, one JSON event per line"),
+		mcp.WithTemplateMIMEType(ndjsonMIMEType),
+	)
+	return template, r.resultsResourceHandler
+}
+
+func (r *GadgetToolRegistry) resultsResourceHandler(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	id, _ := request.Params.Arguments["id"].(string)
+	if id == "" {
+		id = strings.TrimPrefix(request.Params.URI, resultsURIPrefix)
+	}
+	if id == "" {
+		return nil, fmt.Errorf("invalid gadget results URI %q, expected %s", request.Params.URI, resultsURITemplate)
+	}
+	output, ok := r.storedResults.get(id)
+	if !ok {
+		resp, err := r.gadgetMgr.Results(id, "")
+		if err != nil {
+			return nil, fmt.Errorf("getting results of gadget with id %q: %w", id, err)
+		}
+		output = resp.Output
+	}
+	maxLen, _ := r.sessionResultLen(ctx)
+	output, _ = truncateEventLines(output, maxLen)
+	r.accountResults(ctx, len(output))
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      resultsURI(id),
+			MIMEType: ndjsonMIMEType,
+			Text:     output,
+		},
+	}, nil
+}
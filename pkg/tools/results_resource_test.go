@@ -0,0 +1,84 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestResultsResource(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	res, err := callTool(t, r, map[string]any{"background": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	instances, err := mgr.ListInstances(context.Background())
+	if err != nil || len(instances) != 1 {
+		t.Fatalf("expected one instance, got %v, %v", instances, err)
+	}
+	uri := resultsURI(instances[0].ID)
+	if !strings.Contains(resultText(t, res), uri) {
+		t.Errorf("expected the response to mention %s, got %s", uri, resultText(t, res))
+	}
+
+	tmpl, handler := r.ResultsResourceTemplate()
+	if tmpl.MIMEType != "application/x-ndjson" {
+		t.Errorf("unexpected MIME type %q", tmpl.MIMEType)
+	}
+	var request mcp.ReadResourceRequest
+	request.Params.URI = uri
+	contents, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text, ok := contents[0].(mcp.TextResourceContents)
+	if len(contents) != 1 || !ok || text.URI != uri || text.Text != mgr.Result.Output {
+		t.Errorf("unexpected resource contents %+v", contents)
+	}
+
+	request.Params.URI = resultsURI("unknown")
+	if _, err := handler(context.Background(), request); err == nil {
+		t.Errorf("expected an error for an unknown gadget instance")
+	}
+
+	// The same limit as for the tool results applies, leaving out whole events
+	mgr.Result.Output = strings.Repeat("{\"name\":\"example.com.\"}\n", 100)
+	r.resultLimit.Store(100)
+	request.Params.URI = uri
+	if contents, err = handler(context.Background(), request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := contents[0].(mcp.TextResourceContents).Text; len(text) > 100 || !strings.HasSuffix(text, "}\n") {
+		t.Errorf("expected the results to be truncated to whole events, got %q", text)
+	}
+}
+
+func TestResultStore(t *testing.T) {
+	var s resultStore
+	first := s.put("first")
+	if got, ok := s.get(first); !ok || got != "first" {
+		t.Errorf("expected the stored results, got %q, %v", got, ok)
+	}
+	for range maxStoredResults {
+		s.put("more")
+	}
+	if _, ok := s.get(first); ok {
+		t.Error("expected the oldest results to be dropped")
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestSelectFields(t *testing.T) {
+	result := &gadgetmanager.RunResult{
+		Output: "{\"name\":\"a.com.\",\"qr\":\"Q\",\"k8s\":{\"node\":\"n1\",\"podName\":\"p1\"}}\n",
+		Events: 1,
+	}
+	selected, err := selectFields(result, []string{"name", "k8s.podName", "missing"})
+	if err != nil {
+		t.Fatalf("selecting fields: %v", err)
+	}
+	expected := "{\"k8s\":{\"podName\":\"p1\"},\"name\":\"a.com.\"}\n"
+	if selected.Output != expected || selected.Events != 1 {
+		t.Errorf("expected %q, got %q", expected, selected.Output)
+	}
+
+	r, _ := newTestRegistry(t)
+	res, err := callTool(t, r, map[string]any{"fields": "name,unknown"})
+	if err != nil {
+		t.Fatalf("calling tool: %v", err)
+	}
+	if !res.IsError || !strings.Contains(resultText(t, res), "unknown field") {
+		t.Errorf("expected an unknown field error, got %v", res)
+	}
+}
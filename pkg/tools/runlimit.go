@@ -0,0 +1,58 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// runQueueTimeout is how long a gadget run waits for a slot when the maximum of concurrent runs is reached, it's a
+// variable so tests don't have to wait
+var runQueueTimeout = 5 * time.Second
+
+// errServerBusy is returned when no run slot became available in time, see WithMaxConcurrentRuns.
+var errServerBusy = errors.New("server busy: the maximum number of concurrent gadget runs is reached, try again later")
+
+// WithMaxConcurrentRuns limits the number of foreground gadget runs at the same time to protect the backend. Runs
+// over the limit wait briefly for a slot and fail with a server busy error otherwise. Zero, the default, means no
+// limit.
+func WithMaxConcurrentRuns(max int) Option {
+	return func(r *GadgetToolRegistry) {
+		r.runSlots = nil
+		if max > 0 {
+			r.runSlots = make(chan struct{}, max)
+		}
+	}
+}
+
+// acquireRun waits for a run slot, returning a function to release it. It fails with errServerBusy if no slot became
+// available in time, or with the error of ctx if it's done first e.g. because the client cancelled the request.
+func (r *GadgetToolRegistry) acquireRun(ctx context.Context) (func(), error) {
+	if r.runSlots == nil {
+		return func() {}, nil
+	}
+	timer := time.NewTimer(runQueueTimeout)
+	defer timer.Stop()
+	select {
+	case r.runSlots <- struct{}{}:
+		return func() { <-r.runSlots }, nil
+	case <-timer.C:
+		return nil, errServerBusy
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
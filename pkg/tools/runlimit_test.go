@@ -0,0 +1,57 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMaxConcurrentRuns(t *testing.T) {
+	r, _ := newTestRegistry(t, WithMaxConcurrentRuns(1))
+	orig := runQueueTimeout
+	defer func() { runQueueTimeout = orig }()
+	runQueueTimeout = 10 * time.Millisecond
+
+	release, err := r.acquireRun(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error acquiring the first run: %v", err)
+	}
+	if _, err := r.acquireRun(context.Background()); !errors.Is(err, errServerBusy) {
+		t.Errorf("expected errServerBusy when all slots are used, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.acquireRun(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error when the request is cancelled while waiting, got %v", err)
+	}
+	res, err := r.handlerFromGadgetInfo(testGadgetInfo())(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]any{"params": map[string]any{}}},
+	})
+	if err != nil || !res.IsError {
+		t.Errorf("expected a server busy tool error, got %v, %v", res, err)
+	}
+
+	release()
+	release, err = r.acquireRun(context.Background())
+	if err != nil {
+		t.Fatalf("expected a slot after releasing one, got %v", err)
+	}
+	release()
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"time"
+)
+
+// selfCheckTimeout bounds the time SelfCheck waits for the backend
+const selfCheckTimeout = 10 * time.Second
+
+// selfCheckResult is the outcome of SelfCheck, it's reported by the environment-info tool.
+type selfCheckResult struct {
+	OK bool `json:"ok"`
+	// Error tells what to fix if the backend can't be reached
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// SelfCheck verifies that the Inspektor Gadget backend can be reached with the same check as the environments tool. A
+// failure doesn't prevent the server from working, e.g. Inspektor Gadget can still be deployed with the deploy tool, so
+// the error is only returned for logging and reported by the environment-info tool.
+func (r *GadgetToolRegistry) SelfCheck(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, selfCheckTimeout)
+	defer cancel()
+
+	res := selfCheckResult{OK: true, CheckedAt: time.Now()}
+	err := r.checkEnvironment(ctx)
+	if err != nil {
+		res = selfCheckResult{Error: err.Error(), CheckedAt: res.CheckedAt}
+	}
+	r.selfCheckMu.Lock()
+	r.selfCheck = &res
+	r.selfCheckMu.Unlock()
+	return err
+}
+
+// lastSelfCheck returns the result of the last SelfCheck, nil if it didn't run.
+func (r *GadgetToolRegistry) lastSelfCheck() *selfCheckResult {
+	r.selfCheckMu.Lock()
+	defer r.selfCheckMu.Unlock()
+	return r.selfCheck
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestSelfCheck(t *testing.T) {
+	r, mgr := newTestRegistry(t, WithEnvironmentInfo(EnvironmentInfo{Runtime: gadgetmanager.RuntimeGrpcLinux}))
+	if r.lastSelfCheck() != nil {
+		t.Fatalf("expected no self-check result before running it")
+	}
+	if err := r.SelfCheck(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res := r.lastSelfCheck(); res == nil || !res.OK {
+		t.Errorf("expected a successful self-check, got %+v", res)
+	}
+
+	mgr.Err = errors.New("connection refused")
+	if err := r.SelfCheck(context.Background()); err == nil || !strings.Contains(err.Error(), "ig daemon") {
+		t.Errorf("expected an actionable error, got %v", err)
+	}
+	res, err := r.environmentInfoHandler()(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !strings.Contains(text, `"ok":false`) || !strings.Contains(text, "connection refused") {
+		t.Errorf("expected the failed self-check to be reported, got %s", text)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
+)
+
+var serverHelpTmpl = template.Must(template.New("serverHelp.tmpl").Funcs(template.FuncMap{
+	"cell": func(s string) string {
+		return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+	},
+	"inc": func(i int) int {
+		return i + 1
+	},
+}).ParseFS(templates, "templates/serverHelp.tmpl"))
+
+type serverHelp struct {
+	Kubernetes bool
+	Steps      []string
+	Gadgets    []string
+	Tools      []serverHelpTool
+}
+
+type serverHelpTool struct {
+	Name    string
+	Summary string
+}
+
+func (r *GadgetToolRegistry) newServerHelpTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Returns a short guide to using this server as markdown: the typical workflow to run gadgets " +
+			"in the current environment, the registered gadget tools and the other available tools. Use it when unsure " +
+			"how to get started."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"server-help",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.serverHelpHandler,
+	}
+}
+
+func (r *GadgetToolRegistry) serverHelpHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var out bytes.Buffer
+	if err := serverHelpTmpl.Execute(&out, r.serverHelp()); err != nil {
+		return nil, fmt.Errorf("rendering server help: %w", err)
+	}
+	return mcp.NewToolResultText(out.String()), nil
+}
+
+// serverHelp collects the data of the server-help guide from the registered tools, so that it only mentions the
+// tools that can actually be called.
+func (r *GadgetToolRegistry) serverHelp() serverHelp {
+	r.mu.Lock()
+	help := serverHelp{Kubernetes: r.environment() == deployer.KubernetesEnv}
+	registered := make(map[string]bool)
+	for key, tool := range r.tools {
+		registered[tool.Tool.Name] = true
+		if slices.Contains(r.images, key) {
+			help.Gadgets = append(help.Gadgets, tool.Tool.Name)
+			continue
+		}
+		summary, _, _ := strings.Cut(tool.Tool.Description, ". ")
+		help.Tools = append(help.Tools, serverHelpTool{Name: tool.Tool.Name, Summary: strings.TrimSuffix(summary, ".")})
+	}
+	r.mu.Unlock()
+	slices.Sort(help.Gadgets)
+	slices.SortFunc(help.Tools, func(a, b serverHelpTool) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if help.Kubernetes && registered["is_inspektor_gadget_deployed"] && registered["deploy_inspektor_gadget"] {
+		help.Steps = append(help.Steps, "Check if Inspektor Gadget is deployed with `is_inspektor_gadget_deployed`, "+
+			"and deploy it with `deploy_inspektor_gadget` if it isn't")
+	}
+	example := "a gadget tool"
+	if len(help.Gadgets) > 0 {
+		example = fmt.Sprintf("a gadget tool, e.g. `%s`", help.Gadgets[0])
+	}
+	help.Steps = append(help.Steps, fmt.Sprintf("Run %s. By default it runs for a few seconds and returns the events, "+
+		"pass `background: true` to keep it running and get its ID instead", example))
+	if registered["get-results"] {
+		help.Steps = append(help.Steps, "Get the events collected by a background run with `get-results` and its ID")
+	}
+	if registered["stop-gadget"] {
+		help.Steps = append(help.Steps, "Stop the background run with `stop-gadget` once done")
+	}
+	return help
+}
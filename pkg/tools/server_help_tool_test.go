@@ -0,0 +1,48 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestServerHelp(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	r.images = []string{"trace_dns:latest"}
+	r.tools["trace_dns:latest"] = server.ServerTool{Tool: mcp.NewTool("trace_dns")}
+	for _, tool := range []server.ServerTool{r.newStopTool(), r.newGetResultsTool()} {
+		r.tools[tool.Tool.Name] = tool
+	}
+
+	res, err := r.serverHelpHandler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(t, res)
+	for _, s := range []string{"e.g. `trace_dns`", "with `get-results`", "with `stop-gadget`", "| `stop-gadget` | Stops a gadget with an ID |"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("expected the help to contain %q, got:\n%s", s, text)
+		}
+	}
+	// Tools that aren't registered aren't mentioned
+	if strings.Contains(text, "deploy_inspektor_gadget") {
+		t.Errorf("expected the help not to mention unregistered tools, got:\n%s", text)
+	}
+}
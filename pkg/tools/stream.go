@@ -0,0 +1,139 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+const (
+	streamInterval = 2 * time.Second
+	// eventsNotification is the method of the notifications carrying the events of a streamed gadget instance
+	eventsNotification = "notifications/gadget/events"
+)
+
+// WithStreaming enables the stream argument of gadget tools, pushing the events of background runs to the client as
+// notifications. It must only be enabled for the transports that can push notifications to the client outside of
+// requests, i.e. SSE and streamable-HTTP.
+func WithStreaming(enabled bool) Option {
+	return func(r *GadgetToolRegistry) {
+		r.streaming = enabled
+	}
+}
+
+func (r *GadgetToolRegistry) canStream() bool {
+	return r.streaming
+}
+
+// notifySender sends a notification to the client, see server.MCPServer.SendNotificationToClient
+type notifySender func(ctx context.Context, method string, params map[string]any) error
+
+// eventStream forwards the events of a background gadget instance, received by subscribing to its data sources, to the
+// client as notifications. Events are batched every streamInterval and the ones that don't fit in maxLen bytes per
+// notification are dropped and counted.
+type eventStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	send   notifySender
+	maxLen int
+	done   chan struct{}
+
+	mu      sync.Mutex
+	lines   []string
+	size    int
+	dropped int
+	err     error
+}
+
+// newEventStream creates a stream for the session of ctx, it outlives the request starting the gadget.
+func newEventStream(ctx context.Context, send notifySender, maxLen int) *eventStream {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	return &eventStream{ctx: ctx, cancel: cancel, send: send, maxLen: maxLen, done: make(chan struct{})}
+}
+
+// runOption subscribes the stream to the gadget instance started by RunDetached.
+func (s *eventStream) runOption() gadgetmanager.RunOption {
+	return gadgetmanager.WithSubscriber(s.ctx, s.add, s.finish)
+}
+
+func (s *eventStream) add(event string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size+len(event)+1 > s.maxLen {
+		s.dropped++
+		return
+	}
+	s.lines = append(s.lines, event)
+	s.size += len(event) + 1
+}
+
+func (s *eventStream) finish(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	close(s.done)
+}
+
+// run sends the events of the instance with the given ID until its subscription ends, the last notification is marked
+// as done. It stops the subscription if a notification can't be sent, e.g. because the client is gone.
+func (s *eventStream) run(id string) {
+	defer s.cancel()
+	ticker := time.NewTicker(streamInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.flush(id, false); err != nil {
+				log.Debug("Stopping result streaming", "id", id, "error", err)
+				return
+			}
+		case <-s.done:
+			if err := s.flush(id, true); err != nil {
+				log.Debug("Failed to send the last streamed results", "id", id, "error", err)
+			}
+			return
+		}
+	}
+}
+
+func (s *eventStream) flush(id string, last bool) error {
+	s.mu.Lock()
+	lines, dropped, streamErr := s.lines, s.dropped, s.err
+	s.lines, s.size, s.dropped = nil, 0, 0
+	s.mu.Unlock()
+	if len(lines) == 0 && dropped == 0 && !last {
+		return nil
+	}
+	params := map[string]any{
+		"id":     id,
+		"events": len(lines),
+		"data":   strings.Join(lines, "\n"),
+	}
+	if dropped > 0 {
+		params["dropped"] = dropped
+	}
+	if last {
+		params["done"] = true
+		if streamErr != nil {
+			params["error"] = streamErr.Error()
+		}
+	}
+	return s.send(s.ctx, eventsNotification, params)
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager/gadgetmanagertest"
+)
+
+func TestEventStream(t *testing.T) {
+	var mu sync.Mutex
+	var sent []map[string]any
+	send := func(ctx context.Context, method string, params map[string]any) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if method != eventsNotification {
+			t.Errorf("unexpected notification %q", method)
+		}
+		sent = append(sent, params)
+		return nil
+	}
+
+	mgr := gadgetmanagertest.NewFakeGadgetManager()
+	mgr.Result.Output = "{\"name\":\"a\"}\n{\"name\":\"b\"}\n{\"name\":\"c\"}\n"
+	// Only two events fit in a notification
+	s := newEventStream(context.Background(), send, 26)
+	id, err := mgr.RunDetached("trace_dns:latest", nil, nil, s.runOption())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	finished := make(chan struct{})
+	go func() {
+		s.run(id)
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the stream to end with the subscription")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 1 {
+		t.Fatalf("expected a single notification, got %v", sent)
+	}
+	n := sent[0]
+	if n["id"] != id || n["events"] != 2 || n["dropped"] != 1 || n["done"] != true || n["data"] != "{\"name\":\"a\"}\n{\"name\":\"b\"}" {
+		t.Errorf("unexpected notification %v", n)
+	}
+	if s.ctx.Err() == nil {
+		t.Error("expected the stream context to be cancelled once it ended")
+	}
+}
+
+func TestEventStreamSendError(t *testing.T) {
+	s := newEventStream(context.Background(), func(context.Context, string, map[string]any) error {
+		return errors.New("session closed")
+	}, 1024)
+	s.add("{}")
+	go s.run("id")
+	select {
+	case <-s.ctx.Done():
+	case <-time.After(2*streamInterval + time.Second):
+		t.Error("expected the subscription to be cancelled once a notification can't be sent")
+	}
+}
+
+func TestHandlerStream(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	tool, err := r.toolFromGadgetInfo(testGadgetInfo())
+	if err != nil {
+		t.Fatalf("creating tool: %v", err)
+	}
+	if _, ok := tool.InputSchema.Properties["stream"]; ok {
+		t.Errorf("expected no stream argument unless streaming is enabled")
+	}
+	res, err := callTool(t, r, map[string]any{"background": true, "stream": true})
+	if err != nil {
+		t.Fatalf("calling tool: %v", err)
+	}
+	if !res.IsError || len(mgr.DetachedCalls()) != 0 {
+		t.Errorf("expected streaming to be rejected over stdio, got %v", res)
+	}
+
+	r, mgr = newTestRegistry(t, WithStreaming(true))
+	if tool, _ = r.toolFromGadgetInfo(testGadgetInfo()); tool.InputSchema.Properties["stream"] == nil {
+		t.Errorf("expected a stream argument with streaming enabled")
+	}
+	// Without an MCP server in the context, there is no session to stream to
+	if res, err = callTool(t, r, map[string]any{"background": true, "stream": true}); err != nil || !res.IsError {
+		t.Errorf("expected streaming to be rejected without a session, got %v, %v", res, err)
+	}
+}
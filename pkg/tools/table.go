@@ -0,0 +1,106 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+	// formatNDJSON returns the events as an embedded resource, see ndjsonResult
+	formatNDJSON = "ndjson"
+
+	// maxTableColumns is the number of columns of a table when no fields are selected
+	maxTableColumns = 10
+)
+
+var resultFormats = []string{formatJSON, formatTable, formatNDJSON}
+
+// renderTable renders the events of a result as a markdown table, with nested fields flattened to their full name
+// e.g. k8s.podName. The columns are the given ones, or the first maxTableColumns fields by name. Rows that would make
+// the table longer than maxLen are left out, which is noted below it.
+func renderTable(result *gadgetmanager.RunResult, columns []string, maxLen int) (*gadgetmanager.RunResult, error) {
+	var rows []map[string]string
+	var names []string
+	for _, line := range gadgetmanager.EventLines(result.Output) {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return nil, fmt.Errorf("decoding event: %w", err)
+		}
+		row := make(map[string]string)
+		flattenEvent(row, "", event)
+		for name := range row {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+		rows = append(rows, row)
+	}
+	if len(columns) == 0 {
+		slices.Sort(names)
+		columns = names[:min(len(names), maxTableColumns)]
+	}
+
+	var out strings.Builder
+	out.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+	out.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for i, row := range rows {
+		cells := make([]string, 0, len(columns))
+		for _, c := range columns {
+			cells = append(cells, escapeTableCell(row[c]))
+		}
+		line := "| " + strings.Join(cells, " | ") + " |\n"
+		if out.Len()+len(line) > maxLen {
+			fmt.Fprintf(&out, "\n%d more rows not shown\n", len(rows)-i)
+			break
+		}
+		out.WriteString(line)
+	}
+	if len(columns) < len(names) {
+		fmt.Fprintf(&out, "\n%d more fields not shown, use the fields argument to choose the columns\n", len(names)-len(columns))
+	}
+
+	table := *result
+	table.Output = out.String()
+	return &table, nil
+}
+
+// flattenEvent sets the values of event in row by their full name, formatting non-string values as JSON.
+func flattenEvent(row map[string]string, prefix string, event map[string]any) {
+	for k, v := range event {
+		name := prefix + k
+		switch v := v.(type) {
+		case map[string]any:
+			flattenEvent(row, name+".", v)
+		case string:
+			row[name] = v
+		default:
+			b, _ := json.Marshal(v)
+			row[name] = string(b)
+		}
+	}
+}
+
+func escapeTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+)
+
+func TestRenderTable(t *testing.T) {
+	result := &gadgetmanager.RunResult{
+		Output: "{\"name\":\"a|b.com.\",\"qr\":\"Q\",\"k8s\":{\"node\":\"n1\"},\"count\":2}\n" +
+			"{\"name\":\"c.com.\",\"qr\":\"R\",\"k8s\":{\"node\":\"n2\"},\"count\":1}\n",
+		Events: 2,
+	}
+	table, err := renderTable(result, nil, maxResultLen)
+	if err != nil {
+		t.Fatalf("rendering table: %v", err)
+	}
+	expected := "| count | k8s.node | name | qr |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| 2 | n1 | a\\|b.com. | Q |\n" +
+		"| 1 | n2 | c.com. | R |\n"
+	if table.Output != expected {
+		t.Errorf("expected table:\n%s\ngot:\n%s", expected, table.Output)
+	}
+
+	table, err = renderTable(result, []string{"name"}, 35)
+	if err != nil {
+		t.Fatalf("rendering table: %v", err)
+	}
+	if !strings.HasPrefix(table.Output, "| name |\n| --- |\n| a\\|b.com. |\n") || !strings.Contains(table.Output, "1 more rows not shown") {
+		t.Errorf("expected a single row with a notice, got:\n%s", table.Output)
+	}
+
+	r, _ := newTestRegistry(t)
+	res, err := callTool(t, r, map[string]any{"format": "table", "background": true})
+	if err != nil {
+		t.Fatalf("calling tool: %v", err)
+	}
+	if !res.IsError {
+		t.Errorf("expected an error for a table in the background, got %v", res)
+	}
+}
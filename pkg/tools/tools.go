@@ -17,12 +17,17 @@ package tools
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log/slog"
+	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"text/template"
 	"time"
 
@@ -35,15 +40,27 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/deployer"
 	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/logging"
 )
 
-const maxResultLen = 64 * 1024 // 64kb
+const (
+	maxResultLen         = 64 * 1024 // 64kb
+	defaultGadgetTimeout = 10 * time.Second
+	defaultInfoTimeout   = 15 * time.Second
+	defaultPodSelector   = "k8s-app=gadget"
+)
+
+const descriptionTemplateName = "toolDescription.tmpl"
+
+// mapFetchIntervalParam is the param controlling how often map gadgets emit their events
+const mapFetchIntervalParam = "operator.oci.ebpf.map-fetch-interval"
 
 //go:embed templates
 var templates embed.FS
 
-var log = slog.Default().With("component", "tools")
+var log = logging.Default().With("component", "tools")
 
 type ToolRegistryCallback func(tool ...server.ServerTool)
 
@@ -53,26 +70,235 @@ type GadgetToolRegistry struct {
 	mu        sync.Mutex
 	callbacks []ToolRegistryCallback
 	gadgetMgr gadgetmanager.GadgetManager
+	userAgent string
+	// descriptionTmpl holds the tool description templates, see ParseDescriptionTemplates
+	descriptionTmpl *template.Template
+	// strictImages makes invalid or unresolvable gadget images fail the registration instead of being skipped
+	strictImages bool
+	// defaultTimeout is used for foreground runs without an explicit timeout
+	defaultTimeout time.Duration
+	// mapFetchIntervalOverride controls whether map-fetch-interval is set to half the timeout for foreground runs
+	mapFetchIntervalOverride bool
+	// defaultNamespace is used for gadget runs that don't specify a namespace, see set-default-namespace
+	defaultNamespace string
+	nsMu             sync.RWMutex
+	// verifyGadgets is passed to the deployer when set, see WithVerifyGadgets
+	verifyGadgets *bool
+	// deployRetries is passed to the deployer when set, see WithDeployRetries
+	deployRetries *int
+	// resultBudgetBytes is the amount of result bytes per session before results get truncated more, see WithResultBudget
+	resultBudgetBytes int
+	resultBudget      resultBudget
+	// nestedParams groups gadget params by operator prefix in the tool schema, see WithNestedParams
+	nestedParams bool
+	// infoTimeout bounds the time to get the info of a single gadget image during registration
+	infoTimeout time.Duration
+	// envInfo is reported by the environment-info tool
+	envInfo EnvironmentInfo
+	// lastErrors holds the last error of running each gadget image, see last-error
+	lastErrors map[string]runError
+	lastErrMu  sync.Mutex
+	// podSelector is the label selector of the Inspektor Gadget pods, used to detect if it's deployed
+	podSelector string
+	// igNamespace is the namespace Inspektor Gadget is expected in, see WithIGNamespace
+	igNamespace string
+	// customTools are added by RegisterCustomTool and registered along with the built-in tools in Prepare
+	customTools []server.ServerTool
+	prepared    bool
+	// maxGadgetTools limits the number of gadget tools registered, see WithMaxGadgetTools
+	maxGadgetTools int
+	// lazyGadgetTools defers getting the gadget info to the first call of each tool, see WithLazyGadgetTools
+	lazyGadgetTools bool
+	// infoCache tells if the gadget manager caches the gadget info, the warm-cache tool is only offered then, see
+	// WithInfoCache
+	infoCache bool
+	// images are the gadget images given to Prepare or Reload, their tools are keyed by image
+	images []string
+	// chartURL is the Inspektor Gadget chart deployed by the deploy tools, see WithChartURL
+	chartURL string
+	// categories are the categories of the gadget images, see SetGadgetCategories
+	categories map[string][]string
+	// skipRegisterAfterDeploy leaves registering the gadget tools to refresh-gadgets, see WithRegisterAfterDeploy
+	skipRegisterAfterDeploy bool
+	// resultLimit overrides maxResultLen if positive, see set-result-limit
+	resultLimit atomic.Int64
+	// runSlots limits the number of concurrent foreground runs, see WithMaxConcurrentRuns
+	runSlots chan struct{}
+	// backgroundRuns and pausedRuns hold the parameters of background runs by ID, see pause-gadget
+	backgroundRuns map[string]backgroundRun
+	pausedRuns     map[string]backgroundRun
+	runsMu         sync.Mutex
+	// paramEnv are the environment variables gadget param values can reference, see WithParamEnv
+	paramEnv []string
+	// globalFilter is applied to every gadget run, see WithGlobalFilter
+	globalFilter string
+	// streaming enables the stream argument of gadget tools, see WithStreaming
+	streaming bool
+	// storedResults are the foreground results returned as resources, see ResultsResourceTemplate
+	storedResults resultStore
+	// selfCheck is the result of the last SelfCheck
+	selfCheck   *selfCheckResult
+	selfCheckMu sync.Mutex
 }
 
+// Option configures a GadgetToolRegistry.
+type Option func(*GadgetToolRegistry)
+
 type ToolData struct {
 	Name        string
 	Description string
 	Environment string
 	Fields      []FieldData
+	Params      []ParamData
 }
 
 type FieldData struct {
 	Name           string
+	Type           string
+	Description    string
+	PossibleValues string
+}
+
+type ParamData struct {
+	Name           string
+	Type           string
+	DefaultValue   string
 	Description    string
 	PossibleValues string
 }
 
 // NewToolRegistry creates a new GadgetToolRegistry instance.
-func NewToolRegistry(manager gadgetmanager.GadgetManager) *GadgetToolRegistry {
-	return &GadgetToolRegistry{
-		tools:     make(map[string]server.ServerTool),
-		gadgetMgr: manager,
+func NewToolRegistry(manager gadgetmanager.GadgetManager, opts ...Option) *GadgetToolRegistry {
+	r := &GadgetToolRegistry{
+		tools:                    make(map[string]server.ServerTool),
+		gadgetMgr:                manager,
+		mapFetchIntervalOverride: true,
+		defaultTimeout:           defaultGadgetTimeout,
+		infoTimeout:              defaultInfoTimeout,
+		podSelector:              defaultPodSelector,
+		chartURL:                 defaultChartUrl,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.descriptionTmpl == nil {
+		// The embedded template is known to be valid
+		r.descriptionTmpl = template.Must(ParseDescriptionTemplates(""))
+	}
+	return r
+}
+
+// WithDescriptionTemplates sets the templates used to render the gadget tool descriptions.
+func WithDescriptionTemplates(tmpl *template.Template) Option {
+	return func(r *GadgetToolRegistry) {
+		r.descriptionTmpl = tmpl
+	}
+}
+
+// ParseDescriptionTemplates parses the embedded tool description template and, if dir is set, all *.tmpl files in
+// it. A toolDescription.tmpl file in dir overrides the embedded template, while a <tool_name>.tmpl file is only used
+// for the gadget with that tool name.
+func ParseDescriptionTemplates(dir string) (*template.Template, error) {
+	tmpl, err := template.ParseFS(templates, "templates/"+descriptionTemplateName)
+	if err != nil {
+		return nil, fmt.Errorf("parsing embedded template: %w", err)
+	}
+	if dir == "" {
+		return tmpl, nil
+	}
+	tmpl, err = tmpl.ParseGlob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing templates from %s: %w", dir, err)
+	}
+	return tmpl, nil
+}
+
+// WithDefaultTimeout sets the timeout for foreground gadget runs that don't specify one. As map-fetch-interval is
+// set to half of the timeout, it also changes the default interval for map gadgets.
+func WithDefaultTimeout(timeout time.Duration) Option {
+	return func(r *GadgetToolRegistry) {
+		r.defaultTimeout = timeout
+	}
+}
+
+// WithInfoTimeout sets the timeout to get the info of each gadget image when registering the gadget tools. Images
+// that time out are skipped like the ones that can't be resolved.
+func WithInfoTimeout(timeout time.Duration) Option {
+	return func(r *GadgetToolRegistry) {
+		r.infoTimeout = timeout
+	}
+}
+
+// WithMapFetchIntervalOverride controls whether operator.oci.ebpf.map-fetch-interval is set to half of the timeout
+// for foreground runs. When disabled, the gadget's own default interval is used.
+func WithMapFetchIntervalOverride(enabled bool) Option {
+	return func(r *GadgetToolRegistry) {
+		r.mapFetchIntervalOverride = enabled
+	}
+}
+
+// WithStrictImages makes Prepare fail if any of the gadget images is invalid or can't be resolved. By default, such
+// images are skipped with a warning.
+func WithStrictImages(strict bool) Option {
+	return func(r *GadgetToolRegistry) {
+		r.strictImages = strict
+	}
+}
+
+// WithVerifyGadgets sets whether Inspektor Gadget deployed by the deploy tool verifies gadget image signatures. If
+// not set, the chart default is used.
+func WithVerifyGadgets(verify bool) Option {
+	return func(r *GadgetToolRegistry) {
+		r.verifyGadgets = &verify
+	}
+}
+
+// WithDeployRetries sets how many times transient deploy failures are retried. If not set, the deployer default is
+// used.
+func WithDeployRetries(retries int) Option {
+	return func(r *GadgetToolRegistry) {
+		r.deployRetries = &retries
+	}
+}
+
+// WithPodSelector sets the label selector used to find the Inspektor Gadget pods and DaemonSet, for installations
+// not using the default k8s-app=gadget label.
+func WithPodSelector(selector string) Option {
+	return func(r *GadgetToolRegistry) {
+		r.podSelector = selector
+	}
+}
+
+// WithMaxGadgetTools limits the number of gadget tools to max, to not overwhelm models with small contexts. The tools
+// are registered in the order of the images given to Prepare, skipping the ones that can't be resolved, and the
+// remaining images are ignored. Zero, the default, means no limit.
+func WithMaxGadgetTools(max int) Option {
+	return func(r *GadgetToolRegistry) {
+		r.maxGadgetTools = max
+	}
+}
+
+// WithIGNamespace sets the namespace where Inspektor Gadget is expected to be deployed. Its pods are looked up there
+// first, which avoids listing pods in all namespaces, and in all namespaces if none is found. By default, all
+// namespaces are searched.
+func WithIGNamespace(namespace string) Option {
+	return func(r *GadgetToolRegistry) {
+		r.igNamespace = namespace
+	}
+}
+
+// WithChartURL sets the URL of the Inspektor Gadget Helm chart deployed by the deploy tools, e.g. a mirror. The chart
+// version is appended to it unless it's pinned to a version already, e.g. 'oci://registry.example.com/charts/gadget:0.41.0'.
+func WithChartURL(url string) Option {
+	return func(r *GadgetToolRegistry) {
+		r.chartURL = url
+	}
+}
+
+// WithUserAgent sets the user-agent used for outbound HTTP requests made by tools e.g. deploy.
+func WithUserAgent(userAgent string) Option {
+	return func(r *GadgetToolRegistry) {
+		r.userAgent = userAgent
 	}
 }
 
@@ -88,45 +314,211 @@ func (r *GadgetToolRegistry) RegisterCallback(callback ToolRegistryCallback) {
 	r.callbacks = append(r.callbacks, callback)
 }
 
+// RegisterCustomTool adds a tool that isn't based on a gadget, e.g. from code embedding this package. Tools
+// registered before Prepare are added by it, later ones are added right away and reported to the callbacks. A custom
+// tool never replaces a built-in tool with the same name.
+func (r *GadgetToolRegistry) RegisterCustomTool(tool server.ServerTool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.customTools = append(r.customTools, tool)
+	if !r.prepared {
+		return
+	}
+	if !r.addCustomTool(tool) {
+		return
+	}
+	for _, callback := range r.callbacks {
+		log.Debug("Invoking tool registry callback", "tools_count", len(r.tools))
+		callback(r.all()...)
+	}
+}
+
+func (r *GadgetToolRegistry) addCustomTool(tool server.ServerTool) bool {
+	for _, existing := range r.tools {
+		if existing.Tool.Name == tool.Tool.Name {
+			log.Warn("skipping custom tool with the name of an existing tool", "tool", tool.Tool.Name)
+			return false
+		}
+	}
+	r.tools[tool.Tool.Name] = tool
+	return true
+}
+
 func (r *GadgetToolRegistry) Prepare(ctx context.Context, images []string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	images, err := r.validateImages(images)
+	if err != nil {
+		return err
+	}
 	deployTool := newDeployTool(r, images)
-	undeployTool := newUndeployTool()
-	isDeployed := newIsDeployedTool()
+	undeployTool := newUndeployTool(r)
+	deploymentPlanTool := r.newDeploymentPlanTool()
+	isDeployed := r.newIsDeployedTool()
+	versionTool := r.newVersionTool()
+	resourceUsageTool := r.newResourceUsageTool()
 	waitTool := newWaitTool()
 	stopTool := r.newStopTool()
 	getResultsTool := r.newGetResultsTool()
+	listAllInstancesTool := r.newListAllInstancesTool()
+	gadgetStatusTool := r.newGadgetStatusTool()
+	metadataTool := r.newMetadataTool()
+	helpTool := r.newHelpTool()
+	fieldsTool := r.newFieldsTool()
+	validateFilterTool := r.newValidateFilterTool()
+	setDefaultNamespaceTool := r.newSetDefaultNamespaceTool()
+	clearDefaultNamespaceTool := r.newClearDefaultNamespaceTool()
+	deployAndRunTool := r.newDeployAndRunTool(images)
+	previewTool := r.newPreviewTool(images)
+	tailResultsTool := r.newTailResultsTool()
+	cancelRunTool := r.newCancelRunTool()
+	environmentInfoTool := r.newEnvironmentInfoTool()
+	environmentsTool := r.newEnvironmentsTool()
+	gadgetCategoriesTool := r.newGadgetCategoriesTool()
+	toolCatalogTool := r.newToolCatalogTool()
+	lastErrorTool := r.newLastErrorTool()
+	refreshGadgetsTool := r.newRefreshGadgetsTool()
+	rbacCheckTool := r.newRBACCheckTool()
+	setResultLimitTool := r.newSetResultLimitTool()
+	descriptionTool := r.newDescriptionTool()
+	resolveGadgetTool := r.newResolveGadgetTool()
+	pauseTool := r.newPauseTool()
+	resumeTool := r.newResumeTool()
+	serverHelpTool := r.newServerHelpTool()
+	estimateRunTool := r.newEstimateRunTool()
+	namespaceActivityTool := r.newNamespaceActivityTool()
+	warmCacheTool := r.newWarmCacheTool()
 	r.tools[deployTool.Tool.Name] = deployTool
 	r.tools[undeployTool.Tool.Name] = undeployTool
+	r.tools[deploymentPlanTool.Tool.Name] = deploymentPlanTool
 	r.tools[isDeployed.Tool.Name] = isDeployed
+	if r.environment() == deployer.KubernetesEnv {
+		r.tools[versionTool.Tool.Name] = versionTool
+		r.tools[resourceUsageTool.Tool.Name] = resourceUsageTool
+	}
 	r.tools[waitTool.Tool.Name] = waitTool
 	r.tools[stopTool.Tool.Name] = stopTool
 	r.tools[getResultsTool.Tool.Name] = getResultsTool
+	r.tools[listAllInstancesTool.Tool.Name] = listAllInstancesTool
+	r.tools[gadgetStatusTool.Tool.Name] = gadgetStatusTool
+	r.tools[metadataTool.Tool.Name] = metadataTool
+	r.tools[helpTool.Tool.Name] = helpTool
+	r.tools[fieldsTool.Tool.Name] = fieldsTool
+	r.tools[validateFilterTool.Tool.Name] = validateFilterTool
+	r.tools[setDefaultNamespaceTool.Tool.Name] = setDefaultNamespaceTool
+	r.tools[clearDefaultNamespaceTool.Tool.Name] = clearDefaultNamespaceTool
+	r.tools[deployAndRunTool.Tool.Name] = deployAndRunTool
+	r.tools[previewTool.Tool.Name] = previewTool
+	r.tools[tailResultsTool.Tool.Name] = tailResultsTool
+	r.tools[cancelRunTool.Tool.Name] = cancelRunTool
+	r.tools[environmentInfoTool.Tool.Name] = environmentInfoTool
+	r.tools[environmentsTool.Tool.Name] = environmentsTool
+	r.tools[gadgetCategoriesTool.Tool.Name] = gadgetCategoriesTool
+	r.tools[toolCatalogTool.Tool.Name] = toolCatalogTool
+	r.tools[lastErrorTool.Tool.Name] = lastErrorTool
+	r.tools[refreshGadgetsTool.Tool.Name] = refreshGadgetsTool
+	r.tools[rbacCheckTool.Tool.Name] = rbacCheckTool
+	r.tools[setResultLimitTool.Tool.Name] = setResultLimitTool
+	r.tools[descriptionTool.Tool.Name] = descriptionTool
+	r.tools[resolveGadgetTool.Tool.Name] = resolveGadgetTool
+	r.tools[pauseTool.Tool.Name] = pauseTool
+	r.tools[resumeTool.Tool.Name] = resumeTool
+	r.tools[serverHelpTool.Tool.Name] = serverHelpTool
+	r.tools[estimateRunTool.Tool.Name] = estimateRunTool
+	r.tools[namespaceActivityTool.Tool.Name] = namespaceActivityTool
+	if r.infoCache {
+		r.tools[warmCacheTool.Tool.Name] = warmCacheTool
+	}
+	for _, tool := range r.customTools {
+		r.addCustomTool(tool)
+	}
+	r.prepared = true
+	r.images = images
+
+	if err = r.registerDeployedGadgets(ctx, images); err != nil {
+		return err
+	}
+
+	for _, callback := range r.callbacks {
+		log.Debug("Invoking tool registry callback", "tools_count", len(r.tools))
+		callback(r.all()...)
+	}
+
+	return nil
+}
 
-	// Skip registering gadgets if Inspektor Gadget is not deployed
-	deployed, _, err := isInspektorGadgetDeployed(ctx)
+// Reload replaces the gadget tools with the ones for images, e.g. after discovering the gadgets again, and invokes
+// the callbacks. The other tools are kept, apart from the ones working with the list of images that are recreated.
+func (r *GadgetToolRegistry) Reload(ctx context.Context, images []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.prepared {
+		return errors.New("reloading tool registry: not prepared")
+	}
+	images, err := r.validateImages(images)
 	if err != nil {
-		return fmt.Errorf("checking if Inspektor Gadget is deployed: %w", err)
+		return err
 	}
-	if deployed {
-		err = r.registerGadgets(ctx, images)
-		if err != nil {
-			return fmt.Errorf("registering gadgets: %w", err)
-		}
-	} else {
-		log.Info("Inspektor Gadget is not deployed, skipping gadget registration")
+	for _, img := range r.images {
+		delete(r.tools, img)
+	}
+	r.images = images
+	deployTool := newDeployTool(r, images)
+	deployAndRunTool := r.newDeployAndRunTool(images)
+	previewTool := r.newPreviewTool(images)
+	r.tools[deployTool.Tool.Name] = deployTool
+	r.tools[deployAndRunTool.Tool.Name] = deployAndRunTool
+	r.tools[previewTool.Tool.Name] = previewTool
+
+	if err = r.registerDeployedGadgets(ctx, images); err != nil {
+		return err
 	}
 
 	for _, callback := range r.callbacks {
 		log.Debug("Invoking tool registry callback", "tools_count", len(r.tools))
 		callback(r.all()...)
 	}
+	log.Info("Reloaded gadget tools", "images", len(images))
+	return nil
+}
 
+// registerDeployedGadgets registers the gadget tools for images, skipping them if Inspektor Gadget is not deployed.
+// On Linux, there is no deployment to look for and gadgets are skipped when the daemon can't be reached.
+func (r *GadgetToolRegistry) registerDeployedGadgets(ctx context.Context, images []string) error {
+	var err error
+	if r.envInfo.Runtime != gadgetmanager.RuntimeGrpcLinux {
+		_, err = r.inspektorGadgetNamespace(ctx)
+	}
+	switch {
+	case errors.Is(err, ErrNotDeployed):
+		log.Info("Inspektor Gadget is not deployed, skipping gadget registration")
+	case err != nil:
+		return fmt.Errorf("checking if Inspektor Gadget is deployed: %w", err)
+	default:
+		if err = r.registerGadgets(ctx, images); err != nil {
+			return fmt.Errorf("registering gadgets: %w", err)
+		}
+	}
 	return nil
 }
 
+// Clear removes all tools from the registry and invokes the callbacks with an empty tool set, e.g. on shutdown.
+func (r *GadgetToolRegistry) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools = make(map[string]server.ServerTool)
+	r.prepared = false
+	for _, callback := range r.callbacks {
+		log.Debug("Invoking tool registry callback", "tools_count", 0)
+		callback()
+	}
+}
+
 func (r *GadgetToolRegistry) registerGadgets(ctx context.Context, images []string) error {
+	if r.lazyGadgetTools {
+		return r.registerLazyGadgets(images)
+	}
+
 	sem := make(chan struct{}, 8) // Limit concurrency to 8
 	var wg sync.WaitGroup
 	resultsChan := make(chan struct {
@@ -143,7 +535,9 @@ func (r *GadgetToolRegistry) registerGadgets(ctx context.Context, images []strin
 				wg.Done()
 				<-sem
 			}()
-			info, err := r.gadgetMgr.GetInfo(ctx, image)
+			infoCtx, cancel := context.WithTimeout(ctx, r.infoTimeout)
+			defer cancel()
+			info, err := r.gadgetMgr.GetInfo(infoCtx, image)
 			resultsChan <- struct {
 				img  string
 				info *api.GadgetInfo
@@ -157,25 +551,70 @@ func (r *GadgetToolRegistry) registerGadgets(ctx context.Context, images []strin
 		close(resultsChan)
 	}()
 
+	// Process the results in the order of the images so name collisions are resolved deterministically
+	results := make(map[string]struct {
+		info *api.GadgetInfo
+		err  error
+	}, len(images))
 	for result := range resultsChan {
+		results[result.img] = struct {
+			info *api.GadgetInfo
+			err  error
+		}{info: result.info, err: result.err}
+	}
+
+	var failed, skipped []string
+	var errs []error
+	registered := 0
+	// Tools of different versions of a gadget would have the same name, tell them apart by version
+	versioned := multiVersionImages(images)
+	for _, img := range images {
+		if r.maxGadgetTools > 0 && registered >= r.maxGadgetTools {
+			skipped = append(skipped, img)
+			continue
+		}
+		result := results[img]
 		if result.err != nil {
-			log.Warn("Skipping gadget image due to error", "image", result.img, "error", result.err)
+			log.Debug("Failed to resolve gadget image", "image", img, "error", result.err)
+			failed = append(failed, img)
+			errs = append(errs, fmt.Errorf("%s: %w", img, result.err))
 			continue
 		}
 		info := result.info
 		t, err := r.toolFromGadgetInfo(info)
+		if errors.Is(err, errEmptyToolName) {
+			log.Debug("Failed to derive a tool name for gadget image", "image", img)
+			failed = append(failed, img)
+			errs = append(errs, fmt.Errorf("%s: %w", img, err))
+			continue
+		}
 		if err != nil {
 			return fmt.Errorf("creating tool from gadget info for %s: %w", info.ImageName, err)
 		}
+		if versioned[img] {
+			t.Name = versionedToolName(t.Name, img)
+		}
+		t.Name = r.uniqueToolName(t.Name, img)
 		h := r.handlerFromGadgetInfo(info)
 		st := server.ServerTool{
 			Tool:    t,
 			Handler: h,
 		}
-		log.Debug("Adding tool", "image", info.ImageName, "name", t.Name)
-		r.tools[normalizeToolName(info.ImageName)] = st
+		log.Debug("Adding tool", "image", img, "resolved_image", info.ImageName, "name", t.Name)
+		// Keyed by the given image rather than the resolved one, as the tools are looked up and removed by it
+		r.tools[img] = st
+		registered++
+	}
+	if len(skipped) > 0 {
+		log.Info("Skipping gadget images over the gadget tools limit", "limit", r.maxGadgetTools, "images", skipped)
 	}
 
+	if len(failed) > 0 {
+		if r.strictImages {
+			return fmt.Errorf("resolving gadget images: %w", errors.Join(errs...))
+		}
+		log.Warn("Skipping gadget images that couldn't be resolved", "images", failed, "error", errors.Join(errs...))
+	}
 	return nil
 }
 
@@ -186,9 +625,16 @@ func (r *GadgetToolRegistry) toolFromGadgetInfo(info *api.GadgetInfo) (mcp.Tool,
 	if err != nil {
 		return tool, fmt.Errorf("unmarshalling gadget metadata: %w", err)
 	}
-	tmpl, err := template.ParseFS(templates, "templates/toolDescription.tmpl")
-	if err != nil {
-		return tool, fmt.Errorf("parsing template: %w", err)
+	if metadata == nil {
+		metadata = &metadatav1.GadgetMetadata{}
+	}
+	name := toolName(info, metadata)
+	if name == "" {
+		return tool, errEmptyToolName
+	}
+	tmpl := r.descriptionTmpl.Lookup(name + ".tmpl")
+	if tmpl == nil {
+		tmpl = r.descriptionTmpl.Lookup(descriptionTemplateName)
 	}
 	var fields []FieldData
 	if len(info.DataSources) > 0 {
@@ -202,32 +648,27 @@ func (r *GadgetToolRegistry) toolFromGadgetInfo(info *api.GadgetInfo) (mcp.Tool,
 	}
 	var out bytes.Buffer
 	td := ToolData{
-		Name:        normalizeToolName(metadata.Name),
+		Name:        name,
 		Description: metadata.Description,
-		Environment: "Kubernetes",
+		Environment: environmentTitle(r.environment()),
 		Fields:      fields,
 	}
 	if err = tmpl.Execute(&out, td); err != nil {
 		return tool, fmt.Errorf("executing template for gadget %s: %w", info.ImageName, err)
 	}
-	params := make(map[string]interface{})
-	for _, p := range info.Params {
-		params[p.Prefix+p.Key] = map[string]interface{}{
-			"type":        "string",
-			"description": p.Description,
-		}
-	}
+	params := paramsSchema(info, r.nestedParams)
 
 	opts := []mcp.ToolOption{
 		mcp.WithDescription(out.String()),
-		mcp.WithReadOnlyHintAnnotation(true),
+		// Not read-only as background runs start a gadget instance that keeps running until stopped
+		mcp.WithReadOnlyHintAnnotation(false),
 		mcp.WithObject("params",
 			mcp.Required(),
 			mcp.Description("key-value pairs of parameters to pass to the gadget"),
 			mcp.Properties(params),
 		),
 		mcp.WithNumber("timeout",
-			mcp.Description("Timeout in seconds for the gadget to run"),
+			mcp.Description(fmt.Sprintf("Timeout in seconds for the gadget to run (default %d)", int(r.defaultTimeout.Seconds()))),
 		),
 		mcp.WithBoolean("background",
 			mcp.Description("Run in background, allowing the gadget run continuously until stopped, allowing real-time data or "+
@@ -236,8 +677,54 @@ func (r *GadgetToolRegistry) toolFromGadgetInfo(info *api.GadgetInfo) (mcp.Tool,
 			),
 		),
 	}
+	if r.canStream() {
+		opts = append(opts, mcp.WithBoolean("stream",
+			mcp.Description("For background runs, push new events to the client as '"+eventsNotification+"' notifications "+
+				"until the gadget is stopped, instead of polling them with get-results"),
+		))
+	}
+	// Nodes can only be selected on Kubernetes, the ig daemon runs on a single host
+	if r.environment() == deployer.KubernetesEnv {
+		opts = append(opts, mcp.WithString("node",
+			mcp.Description("Comma-separated list of nodes to run the gadget on, by default it runs on all nodes"),
+		))
+	}
+	opts = append(opts, mcp.WithString("pull",
+		mcp.Description("When to pull the gadget image for this run: 'always' to get the latest published image, "+
+			"'if-not-present' to use a cached one, or 'never'. It only affects the run, the params and fields of this tool "+
+			"are the ones of the image when it was registered. Only set it if the user asks for it, by default the backend decides"),
+		mcp.Enum(pullPolicies...),
+	))
+	if hasParam(info, sortParam) {
+		opts = append(opts, mcp.WithString("sort",
+			mcp.Description("Fields to sort the results by, separated by ','. Prefix a field with '-' to sort in descending order e.g. '-count'"),
+		))
+	}
+	opts = append(opts, mcp.WithString("fields",
+		mcp.Description("Comma-separated list of fields to return, e.g. 'name,k8s.podName', instead of all of them. "+
+			"Only for foreground runs"),
+	))
+	opts = append(opts, mcp.WithString("aggregate",
+		mcp.Description("Field to group the events by, returning the number of events per value instead of the events "+
+			"e.g. 'name' to count DNS queries per domain. Only for foreground runs"),
+	))
+	opts = append(opts, mcp.WithString("format",
+		mcp.Description("Format of the results: 'json' for one JSON event per line (default), 'table' for a markdown "+
+			"table with nested fields flattened, easier to show to the user, or 'ndjson' for the events as an attached "+
+			"application/x-ndjson resource, for clients saving the results to a file. Only for foreground runs"),
+		mcp.Enum(resultFormats...),
+	))
+	opts = append(opts, mcp.WithString("arch",
+		mcp.Description("Architecture to run the gadget image for, by only running it on the nodes of that architecture. "+
+			"Defaults to the architecture of each node. Only set if the user explicitly asks for it"),
+		mcp.Enum(knownArches...),
+	))
+	opts = append(opts, mcp.WithObject("labels",
+		mcp.Description("Labels to attach to the run for correlation, e.g. {\"incident\": \"INC-123\"}. They are returned "+
+			"in the result metadata and, for background runs, listed with the gadget instance. Only set if the user asks for it"),
+	))
 	tool = mcp.NewTool(
-		normalizeToolName(metadata.Name),
+		name,
 		opts...,
 	)
 	return tool, nil
@@ -245,47 +732,209 @@ func (r *GadgetToolRegistry) toolFromGadgetInfo(info *api.GadgetInfo) (mcp.Tool,
 
 func (r *GadgetToolRegistry) handlerFromGadgetInfo(info *api.GadgetInfo) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		timeout := 10 * time.Second
+		timeout := r.defaultTimeout
 		params := defaultParamsFromGadgetInfo(info)
+		if _, ok := params[namespaceParam]; ok {
+			if ns := r.getDefaultNamespace(); ns != "" {
+				params[namespaceParam] = ns
+			}
+		}
 		args := request.GetArguments()
 		background := false
+		var nodes []string
+		var aggregate string
+		var fields []string
+		format := formatJSON
+		stream := false
+		var labels map[string]string
+		var arch string
 		if args != nil {
-			if t, ok := args["background"]; ok {
-				background = t.(bool)
+			var err error
+			if background, err = boolArg(args, "background"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
 			if t, ok := args["timeout"].(float64); ok {
 				timeout = time.Duration(t) * time.Second
 			}
 			// set map-fetch-interval to half of the timeout to limit the volume of data fetched
-			if _, ok := params["operator.oci.ebpf.map-fetch-interval"]; ok && !background {
-				params["operator.oci.ebpf.map-fetch-interval"] = (timeout / 2).String()
+			if _, ok := params[mapFetchIntervalParam]; ok && !background && r.mapFetchIntervalOverride {
+				params[mapFetchIntervalParam] = (timeout / 2).String()
 			}
 			// If params is provided, merge it with the default parameters
 			if p, ok := args["params"].(map[string]interface{}); ok {
-				for k, v := range p {
-					if strVal, ok := v.(string); ok {
-						params[k] = strVal
-					} else {
-						return nil, fmt.Errorf("invalid type for parameter %s: expected string, got %T", k, v)
-					}
+				flat, err := flattenParams(p, r.nestedParams)
+				if err != nil {
+					return nil, err
+				}
+				for k, v := range flat {
+					params[k] = v
+				}
+			}
+			if pull, ok := args["pull"].(string); ok && pull != "" {
+				policy, err := ociPullPolicy(pull)
+				if err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				params[pullParam] = policy
+			}
+			if sortBy, ok := args["sort"].(string); ok && sortBy != "" {
+				if err := validateSort(info, sortBy); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				params[sortParam] = sortBy
+			}
+			if stream, err = boolArg(args, "stream"); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if a, ok := args["aggregate"].(string); ok && a != "" {
+				if err := validateAggregate(info, a); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+				aggregate = a
+			}
+			if f, ok := args["fields"].(string); ok && f != "" {
+				var err error
+				if fields, err = parseFields(info, f); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+			}
+			if f, ok := args["format"].(string); ok && f != "" {
+				if !slices.Contains(resultFormats, f) {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid format %q, valid formats are: %s", f, strings.Join(resultFormats, ", "))), nil
+				}
+				format = f
+			}
+			if l, ok := args["labels"]; ok && l != nil {
+				var err error
+				if labels, err = parseLabels(l); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
 				}
 			}
+			if n, ok := args["node"].(string); ok && n != "" {
+				if r.environment() != deployer.KubernetesEnv {
+					return mcp.NewToolResultError("node is only supported in the Kubernetes environment"), nil
+				}
+				nodes = parseNodes(n)
+				if err := validateNodes(ctx, nodes); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
+			}
+			if a, ok := args["arch"].(string); ok && a != "" {
+				if !slices.Contains(knownArches, a) {
+					return mcp.NewToolResultError(fmt.Sprintf("invalid arch %q, valid ones are: %s", a, strings.Join(knownArches, ", "))), nil
+				}
+				arch = a
+			}
+		}
+		if arch != "" {
+			var err error
+			if nodes, err = r.pinArch(ctx, arch, nodes); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		r.applyGlobalFilter(ctx, info, params)
+		// params keeps the ${NAME} references for logs and last-error, see expandParamEnv
+		runParams, err := r.expandParamEnv(params)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if background && aggregate != "" {
+			return mcp.NewToolResultError("aggregate is only supported for foreground runs"), nil
+		}
+		if background && len(fields) > 0 {
+			return mcp.NewToolResultError("fields is only supported for foreground runs"), nil
+		}
+		if background && format != formatJSON {
+			return mcp.NewToolResultError("format is only supported for foreground runs"), nil
+		}
+		if stream && (!background || !r.canStream()) {
+			return mcp.NewToolResultError("stream is only supported for background runs over the sse and streamable-http transports"), nil
 		}
 
 		if background {
-			id, err := r.gadgetMgr.RunDetached(info.ImageName, params)
+			runOpts := []gadgetmanager.RunOption{gadgetmanager.WithContext(ctx)}
+			var events *eventStream
+			if stream {
+				srv := server.ServerFromContext(ctx)
+				if srv == nil {
+					return mcp.NewToolResultError("stream isn't available for this session"), nil
+				}
+				events = newEventStream(ctx, srv.SendNotificationToClient, r.resultLen())
+				runOpts = append(runOpts, events.runOption())
+			}
+			id, err := r.gadgetMgr.RunDetached(info.ImageName, runParams, nodes, runOpts...)
 			if err != nil {
+				if events != nil {
+					events.cancel()
+				}
+				r.recordRunError(info.ImageName, params, nodes, true, err)
 				return nil, fmt.Errorf("running gadget: %w", err)
 			}
-			return mcp.NewToolResultText(fmt.Sprintf("The gadget has been started with ID %s.", id)), nil
+			if len(labels) > 0 {
+				if err := r.gadgetMgr.SetInstanceLabels(id, labels); err != nil {
+					log.WarnContext(ctx, "Failed to set gadget instance labels", "id", id, "error", err)
+				}
+			}
+			r.trackBackgroundRun(ctx, id, info.ImageName, params, nodes, labels, events != nil)
+			if events != nil {
+				go events.run(id)
+				return mcp.NewToolResultText(fmt.Sprintf("The gadget has been started with ID %s, its events are sent as %s notifications.",
+					id, eventsNotification)), nil
+			}
+			msg := fmt.Sprintf("The gadget has been started with ID %s", id)
+			if arch != "" {
+				msg += fmt.Sprintf(" on the %s nodes", arch)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("%s. Its events can also be read as the resource %s.", msg, resultsURI(id))), nil
 		}
 
-		log.Debug("Running gadget", "image", info.ImageName, "params", params, "timeout", timeout)
-		resp, err := r.gadgetMgr.Run(info.ImageName, params, timeout)
+		release, err := r.acquireRun(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		log.DebugContext(ctx, "Running gadget", "image", info.ImageName, "params", params, "timeout", timeout, "nodes", nodes)
+		counter, stopProgress := r.startProgress(ctx, request)
+		resp, err := r.gadgetMgr.Run(info.ImageName, runParams, timeout, nodes, gadgetmanager.WithEventCounter(counter))
+		stopProgress()
+		release()
 		if err != nil {
+			r.recordRunError(info.ImageName, params, nodes, false, err)
 			return nil, fmt.Errorf("starting gadget %s: %w", info.ImageName, err)
 		}
-		return mcp.NewToolResultText(truncateResults(resp)), nil
+		if resp.Events == 0 && resp.Cancelled {
+			return mcp.NewToolResultText("The gadget run was cancelled before producing any events."), nil
+		}
+		if resp.Events == 0 {
+			return mcp.NewToolResultText(fmt.Sprintf("The gadget ran successfully but produced no events in %s; "+
+				"consider increasing the timeout or checking the filters.", timeout)), nil
+		}
+		switch {
+		case aggregate != "":
+			if resp, err = aggregateResults(resp, aggregate); err != nil {
+				return nil, fmt.Errorf("aggregating results by %s: %w", aggregate, err)
+			}
+		case len(fields) > 0:
+			if resp, err = selectFields(resp, fields); err != nil {
+				return nil, fmt.Errorf("selecting fields of results: %w", err)
+			}
+		}
+		if format == formatTable {
+			var columns []string
+			if aggregate == "" {
+				columns = fields
+			}
+			if resp, err = renderTable(resp, columns, r.resultLen()); err != nil {
+				return nil, fmt.Errorf("rendering results as a table: %w", err)
+			}
+		}
+		resp.Labels = labels
+		resp.Arch = arch
+		if format == formatNDJSON {
+			return r.ndjsonResult(resp), nil
+		}
+		return mcp.NewToolResultText(r.formatResults(ctx, resp)), nil
 	}
 }
 
@@ -297,32 +946,77 @@ func defaultParamsFromGadgetInfo(info *api.GadgetInfo) map[string]string {
 	return params
 }
 
+// errEmptyToolName is returned when no tool name can be derived for a gadget.
+var errEmptyToolName = errors.New("gadget has no name in its metadata nor its image")
+
+// toolName returns the tool name for a gadget. It uses the name from the gadget metadata and falls back to the
+// repository name of the image e.g. trace_dns for ghcr.io/inspektor-gadget/gadget/trace_dns:latest.
+func toolName(info *api.GadgetInfo, metadata *metadatav1.GadgetMetadata) string {
+	if name := strings.TrimSpace(metadata.Name); name != "" {
+		return normalizeToolName(name)
+	}
+	image, _, _ := strings.Cut(info.ImageName, "@")
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	image, _, _ = strings.Cut(image, ":")
+	return normalizeToolName(image)
+}
+
+// invalidToolNameChars matches the characters MCP clients don't allow in tool names
+var invalidToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
 func normalizeToolName(name string) string {
-	// Normalize tool name to lowercase and replace spaces with dashes
-	return strings.ReplaceAll(name, " ", "_")
+	// Replace spaces and other characters not allowed in tool names with underscores
+	return invalidToolNameChars.ReplaceAllString(name, "_")
 }
 
-// A generic function to check if Inspektor Gadget is deployed in the cluster e.g using kubectl-gadget, helm, or other means.
-// It returns a boolean indicating if it is deployed, the namespace it is deployed in, and any error encountered
-func isInspektorGadgetDeployed(ctx context.Context) (bool, string, error) {
-	restConfig, err := utils.KubernetesConfigFlags.ToRESTConfig()
-	if err != nil {
-		return false, "", fmt.Errorf("creating RESTConfig: %w", err)
+// uniqueToolName returns name, or name with a short hash of the image appended if a tool registered for another image
+// or a built-in tool already uses it, so that tools don't silently replace each other.
+func (r *GadgetToolRegistry) uniqueToolName(name, image string) string {
+	for key, tool := range r.tools {
+		if key == image || tool.Tool.Name != name {
+			continue
+		}
+		sum := sha256.Sum256([]byte(image))
+		unique := fmt.Sprintf("%s_%x", name, sum[:3])
+		log.Warn("Gadget tool name already in use, adding a suffix", "image", image, "name", name, "new_name", unique)
+		return unique
 	}
+	return name
+}
 
-	client, err := kubernetes.NewForConfig(restConfig)
+// ErrNotDeployed is returned when Inspektor Gadget is not deployed on the target system.
+var ErrNotDeployed = errors.New("not deployed")
+
+// A generic function to find where Inspektor Gadget is deployed in the cluster e.g using kubectl-gadget, helm, or
+// other means. It returns the namespace it is deployed in, or ErrNotDeployed if it isn't deployed. The namespace
+// set with WithIGNamespace is checked first.
+func (r *GadgetToolRegistry) inspektorGadgetNamespace(ctx context.Context) (string, error) {
+	client, err := newKubernetesClient()
 	if err != nil {
-		return false, "", fmt.Errorf("setting up trace client: %w", err)
+		return "", err
 	}
 
-	opts := metav1.ListOptions{LabelSelector: "k8s-app=gadget"}
+	opts := metav1.ListOptions{LabelSelector: r.podSelector}
+	if r.igNamespace != "" {
+		pods, err := client.CoreV1().Pods(r.igNamespace).List(ctx, opts)
+		switch {
+		case err == nil && len(pods.Items) > 0:
+			return r.igNamespace, nil
+		case err != nil:
+			log.DebugContext(ctx, "Failed to list Inspektor Gadget pods in namespace, falling back to all namespaces", "namespace", r.igNamespace, "error", err)
+		default:
+			log.DebugContext(ctx, "No Inspektor Gadget pods found in namespace, falling back to all namespaces", "namespace", r.igNamespace)
+		}
+	}
 	pods, err := client.CoreV1().Pods("").List(ctx, opts)
 	if err != nil {
-		return false, "", fmt.Errorf("getting pods: %w", err)
+		return "", fmt.Errorf("getting pods: %w", err)
 	}
 	if len(pods.Items) == 0 {
-		log.Debug("No Inspektor Gadget pods found")
-		return false, "", nil
+		log.DebugContext(ctx, "No Inspektor Gadget pods found")
+		return "", ErrNotDeployed
 	}
 
 	var namespaces []string
@@ -332,15 +1026,76 @@ func isInspektorGadgetDeployed(ctx context.Context) (bool, string, error) {
 		}
 	}
 	if len(namespaces) > 1 {
-		log.Debug("Multiple namespaces found for Inspektor Gadget pods", "namespaces", namespaces)
-		return false, "", fmt.Errorf("multiple namespaces found for Inspektor Gadget pods: %v", namespaces)
+		log.DebugContext(ctx, "Multiple namespaces found for Inspektor Gadget pods", "namespaces", namespaces)
+		return "", fmt.Errorf("multiple namespaces found for Inspektor Gadget pods: %v", namespaces)
 	}
-	return true, namespaces[0], nil
+	return namespaces[0], nil
+}
+
+// resultMetadata is sent along with the results so the assistant can reason about their completeness.
+type resultMetadata struct {
+	Events      int               `json:"events"`
+	DataSources []string          `json:"dataSources"`
+	Nodes       []string          `json:"nodes,omitempty"`
+	Duration    string            `json:"duration"`
+	Truncated   bool              `json:"truncated"`
+	Cancelled   bool              `json:"cancelled,omitempty"`
+	TimedOut    bool              `json:"timedOut,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Arch        string            `json:"arch,omitempty"`
+	Cursor      string            `json:"cursor,omitempty"`
 }
 
-func truncateResults(results string) string {
-	if len(results) > maxResultLen {
-		return fmt.Sprintf("\n<results>%s</results>\n<isTruncated>true</isTruncated>\n", results[:maxResultLen]+"…")
+func newKubernetesClient() (kubernetes.Interface, error) {
+	restConfig, err := utils.KubernetesConfigFlags.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("creating RESTConfig: %w", err)
+	}
+
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("setting up trace client: %w", err)
+	}
+	return client, nil
+}
+
+func newResultMetadata(result *gadgetmanager.RunResult) resultMetadata {
+	return resultMetadata{
+		Events:      result.Events,
+		DataSources: result.DataSources,
+		Nodes:       result.Nodes,
+		Cancelled:   result.Cancelled,
+		TimedOut:    result.TimedOut,
+		Labels:      result.Labels,
+		Arch:        result.Arch,
+		Duration:    result.Duration.Round(time.Millisecond).String(),
+		Cursor:      result.Cursor,
+	}
+}
+
+// truncateOutput cuts output to maxLen at the end of its last line that fits, so that events aren't cut in the
+// middle, or at maxLen if not even the first line fits. It returns whether output was cut.
+func truncateOutput(output string, maxLen int) (string, bool) {
+	if len(output) <= maxLen {
+		return output, false
+	}
+	if i := strings.LastIndexByte(output[:maxLen], '\n'); i >= 0 {
+		return output[:i+1], true
+	}
+	return output[:maxLen], true
+}
+
+func truncateResults(result *gadgetmanager.RunResult, maxLen int) string {
+	results := result.Output
+	md := newResultMetadata(result)
+	if results, md.Truncated = truncateOutput(results, maxLen); md.Truncated {
+		results += "…"
+	}
+	// Marshalling a struct of basic types can't fail
+	mdJson, _ := json.Marshal(md)
+	out := fmt.Sprintf("\n<metadata>%s</metadata>\n<results>%s</results>\n", mdJson, results)
+	if len(result.Messages) > 0 {
+		out += fmt.Sprintf("<messages>\n%s\n</messages>\n", strings.Join(result.Messages, "\n"))
 	}
-	return fmt.Sprintf("\n<results>%s</results>\n", results)
+	return out
 }
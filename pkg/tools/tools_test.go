@@ -0,0 +1,418 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service/api"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager"
+	"github.com/inspektor-gadget/ig-mcp-server/pkg/gadgetmanager/gadgetmanagertest"
+)
+
+func testGadgetInfo() *api.GadgetInfo {
+	return &api.GadgetInfo{
+		ImageName: "trace_dns:latest",
+		Metadata:  []byte("name: trace dns\ndescription: trace DNS requests and responses\n"),
+		Params: []*api.Param{
+			{Prefix: "operator.KubeManager.", Key: "namespace", Description: "Show only data from pods in a given namespace"},
+			{Prefix: "operator.oci.ebpf.", Key: "map-fetch-interval", DefaultValue: "1s"},
+			{Prefix: "operator.sort.", Key: "sort"},
+		},
+		DataSources: []*api.DataSource{
+			{
+				Name: "dns",
+				Fields: []*api.Field{
+					{FullName: "name"},
+					{FullName: "qr"},
+				},
+			},
+		},
+	}
+}
+
+func newTestRegistry(t *testing.T, opts ...Option) (*GadgetToolRegistry, *gadgetmanagertest.FakeGadgetManager) {
+	t.Helper()
+	mgr := gadgetmanagertest.NewFakeGadgetManager(testGadgetInfo())
+	mgr.Result = &gadgetmanager.RunResult{
+		Output:      "{\"name\":\"example.com.\",\"qr\":\"Q\"}\n",
+		Events:      1,
+		DataSources: []string{"dns"},
+		Duration:    time.Second,
+	}
+	return NewToolRegistry(mgr, opts...), mgr
+}
+
+func callTool(t *testing.T, r *GadgetToolRegistry, args map[string]any) (*mcp.CallToolResult, error) {
+	t.Helper()
+	var request mcp.CallToolRequest
+	request.Params.Arguments = args
+	return r.handlerFromGadgetInfo(testGadgetInfo())(context.Background(), request)
+}
+
+func resultText(t *testing.T, res *mcp.CallToolResult) string {
+	t.Helper()
+	if res == nil || len(res.Content) != 1 {
+		t.Fatalf("expected a single content, got %v", res)
+	}
+	text, ok := res.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected text content, got %T", res.Content[0])
+	}
+	return text.Text
+}
+
+func TestToolFromGadgetInfo(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	tool, err := r.toolFromGadgetInfo(testGadgetInfo())
+	if err != nil {
+		t.Fatalf("creating tool: %v", err)
+	}
+
+	if tool.Name != "trace_dns" {
+		t.Errorf("expected tool name %q, got %q", "trace_dns", tool.Name)
+	}
+	for _, s := range []string{"trace DNS requests and responses", "- name", "- qr"} {
+		if !strings.Contains(tool.Description, s) {
+			t.Errorf("expected description to contain %q", s)
+		}
+	}
+	for _, arg := range []string{"params", "timeout", "background", "node", "pull", "sort", "fields", "aggregate", "format", "arch", "labels"} {
+		if _, ok := tool.InputSchema.Properties[arg]; !ok {
+			t.Errorf("expected argument %q", arg)
+		}
+	}
+	params, ok := tool.InputSchema.Properties["params"].(map[string]any)
+	if !ok {
+		t.Fatalf("unexpected params schema: %v", tool.InputSchema.Properties["params"])
+	}
+	props, ok := params["properties"].(map[string]any)
+	if !ok {
+		t.Fatalf("unexpected params properties: %v", params["properties"])
+	}
+	if _, ok := props[namespaceParam]; !ok {
+		t.Errorf("expected param %q", namespaceParam)
+	}
+}
+
+func TestHandlerForeground(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	res, err := callTool(t, r, map[string]any{
+		"timeout": float64(4),
+		"params": map[string]any{
+			namespaceParam: "default",
+		},
+	})
+	if err != nil {
+		t.Fatalf("calling tool: %v", err)
+	}
+
+	calls := mgr.RunCalls()
+	if len(calls) != 1 || len(mgr.DetachedCalls()) != 0 {
+		t.Fatalf("expected a single foreground run, got %d runs and %d detached runs", len(calls), len(mgr.DetachedCalls()))
+	}
+	if calls[0].Timeout != 4*time.Second {
+		t.Errorf("expected timeout 4s, got %s", calls[0].Timeout)
+	}
+	if got := calls[0].Params[namespaceParam]; got != "default" {
+		t.Errorf("expected namespace %q, got %q", "default", got)
+	}
+	if got := calls[0].Params[mapFetchIntervalParam]; got != "2s" {
+		t.Errorf("expected map-fetch-interval to be half of the timeout, got %q", got)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "<results>{\"name\":\"example.com.\"") {
+		t.Errorf("unexpected result: %s", text)
+	}
+}
+
+func TestHandlerBackground(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	res, err := callTool(t, r, map[string]any{
+		"background": true,
+		"params":     map[string]any{},
+	})
+	if err != nil {
+		t.Fatalf("calling tool: %v", err)
+	}
+
+	calls := mgr.DetachedCalls()
+	if len(calls) != 1 || len(mgr.RunCalls()) != 0 {
+		t.Fatalf("expected a single detached run, got %d detached runs and %d runs", len(calls), len(mgr.RunCalls()))
+	}
+	if got := calls[0].Params[mapFetchIntervalParam]; got != "1s" {
+		t.Errorf("expected default map-fetch-interval for background runs, got %q", got)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "started with ID") {
+		t.Errorf("unexpected result: %s", text)
+	}
+}
+
+func TestHandlerParamOverride(t *testing.T) {
+	r, mgr := newTestRegistry(t, WithMapFetchIntervalOverride(false))
+	_, err := callTool(t, r, map[string]any{
+		"params": map[string]any{
+			namespaceParam: "kube-system",
+		},
+	})
+	if err != nil {
+		t.Fatalf("calling tool: %v", err)
+	}
+
+	params := mgr.RunCalls()[0].Params
+	if got := params[namespaceParam]; got != "kube-system" {
+		t.Errorf("expected namespace %q, got %q", "kube-system", got)
+	}
+	if got := params[mapFetchIntervalParam]; got != "1s" {
+		t.Errorf("expected map-fetch-interval to keep its default, got %q", got)
+	}
+
+	_, err = callTool(t, r, map[string]any{
+		"params": map[string]any{
+			namespaceParam: 1.0,
+		},
+	})
+	if err == nil {
+		t.Errorf("expected an error for a non-string param")
+	}
+}
+
+func TestTruncateResults(t *testing.T) {
+	out := truncateResults(&gadgetmanager.RunResult{Output: "short", Events: 1}, maxResultLen)
+	if strings.Contains(out, `"truncated":true`) {
+		t.Errorf("didn't expect short results to be truncated: %s", out)
+	}
+
+	out = truncateResults(&gadgetmanager.RunResult{Output: strings.Repeat("x", maxResultLen+10), Events: 1}, maxResultLen)
+	if !strings.Contains(out, `"truncated":true`) {
+		t.Errorf("expected long results to be truncated")
+	}
+	if len(out) > maxResultLen+256 {
+		t.Errorf("expected truncated results to be bounded, got %d bytes", len(out))
+	}
+}
+
+func TestToolFromGadgetInfoWithoutMetadataName(t *testing.T) {
+	r, _ := newTestRegistry(t)
+
+	for _, tc := range []struct {
+		image    string
+		metadata []byte
+		expected string
+	}{
+		{image: "ghcr.io/inspektor-gadget/gadget/trace_open:latest", metadata: nil, expected: "trace_open"},
+		{image: "trace_exec", metadata: []byte("description: trace exec\n"), expected: "trace_exec"},
+		{image: "localhost:5000/top_file@sha256:" + strings.Repeat("a", 64), metadata: []byte("name: \"\"\n"), expected: "top_file"},
+		{image: "trace_dns@sha256:" + strings.Repeat("b", 64), metadata: nil, expected: "trace_dns"},
+		{image: "trace_tcp:v0.41.0@sha256:" + strings.Repeat("c", 64), metadata: nil, expected: "trace_tcp"},
+	} {
+		info := &api.GadgetInfo{ImageName: tc.image, Metadata: tc.metadata}
+		tool, err := r.toolFromGadgetInfo(info)
+		if err != nil {
+			t.Errorf("creating tool for %s: %v", tc.image, err)
+			continue
+		}
+		if tool.Name != tc.expected {
+			t.Errorf("expected tool name %q for %s, got %q", tc.expected, tc.image, tool.Name)
+		}
+	}
+
+	_, err := r.toolFromGadgetInfo(&api.GadgetInfo{})
+	if !errors.Is(err, errEmptyToolName) {
+		t.Errorf("expected errEmptyToolName, got %v", err)
+	}
+}
+
+func TestUniqueToolName(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	r.tools["trace_dns:v0.40.0"] = server.ServerTool{Tool: mcp.NewTool("trace_dns")}
+
+	if name := r.uniqueToolName("trace_dns", "trace_dns:v0.40.0"); name != "trace_dns" {
+		t.Errorf("expected the tool of the same image to keep its name, got %q", name)
+	}
+	name := r.uniqueToolName("trace_dns", "trace_dns:v0.41.0")
+	if name == "trace_dns" || !strings.HasPrefix(name, "trace_dns_") {
+		t.Errorf("expected a suffixed name for a colliding tool, got %q", name)
+	}
+	if again := r.uniqueToolName("trace_dns", "trace_dns:v0.41.0"); again != name {
+		t.Errorf("expected a stable name, got %q and %q", name, again)
+	}
+
+	if name := normalizeToolName("trace dns.v2"); name != "trace_dns_v2" {
+		t.Errorf("expected invalid characters to be replaced, got %q", name)
+	}
+}
+
+func TestMaxGadgetTools(t *testing.T) {
+	var infos []*api.GadgetInfo
+	for _, name := range []string{"trace_dns", "trace_exec", "trace_open"} {
+		infos = append(infos, &api.GadgetInfo{ImageName: name + ":latest", Metadata: []byte("name: " + name + "\n")})
+	}
+	r := NewToolRegistry(gadgetmanagertest.NewFakeGadgetManager(infos...), WithMaxGadgetTools(2))
+
+	// The unknown image can't be resolved, so it doesn't count against the limit
+	images := []string{"unknown:latest", "trace_open:latest", "trace_dns:latest", "trace_exec:latest"}
+	if err := r.registerGadgets(context.Background(), images); err != nil {
+		t.Fatalf("registering gadgets: %v", err)
+	}
+	var names []string
+	for _, tool := range r.tools {
+		names = append(names, tool.Tool.Name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"trace_dns", "trace_open"}) {
+		t.Errorf("expected the first two resolved gadgets to be registered, got %v", names)
+	}
+}
+
+func TestReadOnlyHints(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	gadgetTool, err := r.toolFromGadgetInfo(testGadgetInfo())
+	if err != nil {
+		t.Fatalf("creating gadget tool: %v", err)
+	}
+	tests := []struct {
+		tool     mcp.Tool
+		readOnly bool
+	}{
+		{gadgetTool, false},
+		{r.lazyTool("trace_dns:latest", "trace_dns").Tool, false},
+		{newDeployTool(r, nil).Tool, false},
+		{newUndeployTool(r).Tool, false},
+		{r.newDeployAndRunTool(nil).Tool, false},
+		{r.newStopTool().Tool, false},
+		{r.newCancelRunTool().Tool, false},
+		{r.newSetDefaultNamespaceTool().Tool, false},
+		{r.newClearDefaultNamespaceTool().Tool, false},
+		{r.newIsDeployedTool().Tool, true},
+		{r.newVersionTool().Tool, true},
+		{r.newResourceUsageTool().Tool, true},
+		{newWaitTool().Tool, true},
+		{r.newGetResultsTool().Tool, true},
+		{r.newTailResultsTool().Tool, true},
+		{r.newListAllInstancesTool().Tool, true},
+		{r.newGadgetStatusTool().Tool, true},
+		{r.newMetadataTool().Tool, true},
+		{r.newHelpTool().Tool, true},
+		{r.newFieldsTool().Tool, true},
+		{r.newValidateFilterTool().Tool, true},
+		{r.newPreviewTool(nil).Tool, true},
+		{r.newEnvironmentInfoTool().Tool, true},
+		{r.newToolCatalogTool().Tool, true},
+		{r.newLastErrorTool().Tool, true},
+		{r.newDeploymentPlanTool().Tool, true},
+		{r.newEnvironmentsTool().Tool, true},
+		{r.newGadgetCategoriesTool().Tool, true},
+		{r.newRefreshGadgetsTool().Tool, false},
+		{r.newRBACCheckTool().Tool, true},
+		{r.newSetResultLimitTool().Tool, false},
+		{r.newDescriptionTool().Tool, true},
+		{r.newResolveGadgetTool().Tool, true},
+		{r.newPauseTool().Tool, false},
+		{r.newResumeTool().Tool, false},
+		{r.newServerHelpTool().Tool, true},
+		{r.newEstimateRunTool().Tool, false},
+		{r.newNamespaceActivityTool().Tool, false},
+		{r.newWarmCacheTool().Tool, true},
+	}
+	for _, tt := range tests {
+		hint := tt.tool.Annotations.ReadOnlyHint
+		if hint == nil || *hint != tt.readOnly {
+			t.Errorf("expected tool %q to have read-only hint %v, got %v", tt.tool.Name, tt.readOnly, hint)
+		}
+	}
+}
+
+func TestReloadNotPrepared(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	if err := r.Reload(context.Background(), []string{"trace_dns:latest"}); err == nil {
+		t.Error("expected reloading a registry that isn't prepared to fail")
+	}
+}
+
+func TestRegisterGadgetsKeyedByImage(t *testing.T) {
+	r, mgr := newTestRegistry(t)
+	info := testGadgetInfo()
+	info.ImageName = "ghcr.io/inspektor-gadget/gadget/trace_dns:latest"
+	mgr.Infos["trace_dns"] = info
+	if err := r.registerGadgets(context.Background(), []string{"trace_dns"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := r.tools["trace_dns"]; !ok {
+		t.Errorf("expected the tool to be keyed by the given image, got %v", slices.Collect(maps.Keys(r.tools)))
+	}
+	if _, ok := r.tools[info.ImageName]; ok {
+		t.Error("expected no tool keyed by the resolved image")
+	}
+}
+
+func TestHandlerBackgroundArg(t *testing.T) {
+	tests := []struct {
+		value      any
+		background bool
+		invalid    bool
+	}{
+		{value: true, background: true},
+		{value: false},
+		{value: "true", background: true},
+		{value: "false"},
+		{value: float64(1), background: true},
+		{value: float64(0)},
+		{value: "yes", invalid: true},
+		{value: float64(2), invalid: true},
+		{value: []any{true}, invalid: true},
+	}
+	for _, tt := range tests {
+		r, mgr := newTestRegistry(t)
+		res, err := callTool(t, r, map[string]any{"background": tt.value})
+		if err != nil {
+			t.Fatalf("unexpected error for %v: %v", tt.value, err)
+		}
+		if res.IsError != tt.invalid {
+			t.Errorf("expected a tool error %v for %v, got %v", tt.invalid, tt.value, res)
+			continue
+		}
+		if !tt.invalid && (len(mgr.DetachedCalls()) == 1) != tt.background {
+			t.Errorf("expected background %v for %v", tt.background, tt.value)
+		}
+	}
+}
+
+func TestToolDescriptionEnvironment(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	tool, err := r.toolFromGadgetInfo(testGadgetInfo())
+	if err != nil {
+		t.Fatalf("creating tool: %v", err)
+	}
+	if !strings.Contains(tool.Description, "in Kubernetes environments") {
+		t.Errorf("expected a Kubernetes description, got %q", tool.Description)
+	}
+
+	r, _ = newTestRegistry(t, WithEnvironmentInfo(EnvironmentInfo{Runtime: gadgetmanager.RuntimeGrpcLinux}))
+	if tool, err = r.toolFromGadgetInfo(testGadgetInfo()); err != nil {
+		t.Fatalf("creating tool: %v", err)
+	}
+	if !strings.Contains(tool.Description, "in Linux environments") || strings.Contains(tool.Description, "Kubernetes") {
+		t.Errorf("expected a Linux description, got %q", tool.Description)
+	}
+}
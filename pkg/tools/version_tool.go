@@ -0,0 +1,83 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (r *GadgetToolRegistry) newVersionTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Get the version of Inspektor Gadget deployed in the Kubernetes cluster. Use it to check if a gadget is compatible with the running Inspektor Gadget. " +
+			"Only available in the Kubernetes environment."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"gadget-version",
+		opts...,
+	)
+
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.versionHandler,
+	}
+}
+
+// versionHandler reads the version from the image tag of the gadget DaemonSet, the tool is only registered in the
+// Kubernetes environment.
+func (r *GadgetToolRegistry) versionHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	ns, err := r.inspektorGadgetNamespace(ctx)
+	if errors.Is(err, ErrNotDeployed) {
+		return mcp.NewToolResultError("Inspektor Gadget is not deployed"), nil
+	}
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	client, err := newKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+	opts := metav1.ListOptions{LabelSelector: r.podSelector}
+	daemonSets, err := client.AppsV1().DaemonSets(ns).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("getting daemonsets: %w", err)
+	}
+	for _, ds := range daemonSets.Items {
+		for _, c := range ds.Spec.Template.Spec.Containers {
+			if c.Name != "gadget" {
+				continue
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Inspektor Gadget version %s is deployed in namespace %s (image %s)", imageTag(c.Image), ns, c.Image)), nil
+		}
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("no gadget DaemonSet found in namespace %s", ns)), nil
+}
+
+// imageTag returns the tag of an image reference, or "latest" if it doesn't have one.
+func imageTag(image string) string {
+	name, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		return name[i+1:]
+	}
+	return "latest"
+}
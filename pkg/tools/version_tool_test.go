@@ -0,0 +1,34 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"testing"
+)
+
+func TestImageTag(t *testing.T) {
+	for image, expected := range map[string]string{
+		"ghcr.io/inspektor-gadget/inspektor-gadget:v0.41.0":                 "v0.41.0",
+		"ghcr.io/inspektor-gadget/inspektor-gadget":                         "latest",
+		"localhost:5000/inspektor-gadget":                                   "latest",
+		"localhost:5000/inspektor-gadget:main":                              "main",
+		"ghcr.io/inspektor-gadget/inspektor-gadget:v0.41.0@sha256:0123abcd": "v0.41.0",
+		"ghcr.io/inspektor-gadget/inspektor-gadget@sha256:0123abcd":         "latest",
+	} {
+		if tag := imageTag(image); tag != expected {
+			t.Errorf("expected tag %q for %s, got %q", expected, image, tag)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// warmCacheConcurrency is the number of gadget images whose info is fetched at the same time
+const warmCacheConcurrency = 4
+
+// WithInfoCache tells the registry that the gadget manager caches the info of gadget images, see
+// gadgetmanager.WithInfoCacheTTL. The warm-cache tool is only registered then, as warming doesn't do anything otherwise.
+func WithInfoCache(enabled bool) Option {
+	return func(r *GadgetToolRegistry) {
+		r.infoCache = enabled
+	}
+}
+
+// WarmCacheResult is the result of WarmInfoCache, the errors are keyed by image.
+type WarmCacheResult struct {
+	Images    int               `json:"images"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// WarmInfoCache fetches the info of all the gadget images given to Prepare or Reload concurrently, so that the gadget
+// manager caches it and the first call of each gadget tool doesn't wait for it. It only helps if the gadget manager
+// caches the info, see WithInfoCache, and the gadget tools are lazy, otherwise Prepare already got the info of every
+// image.
+func (r *GadgetToolRegistry) WarmInfoCache(ctx context.Context) WarmCacheResult {
+	r.mu.Lock()
+	images := slices.Clone(r.images)
+	r.mu.Unlock()
+
+	res := WarmCacheResult{Images: len(images)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, warmCacheConcurrency)
+	for _, image := range images {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			infoCtx, cancel := context.WithTimeout(ctx, r.infoTimeout)
+			defer cancel()
+			_, err := r.gadgetMgr.GetInfo(infoCtx, image)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				res.Failed++
+				if res.Errors == nil {
+					res.Errors = make(map[string]string)
+				}
+				res.Errors[image] = err.Error()
+				return
+			}
+			res.Succeeded++
+		}()
+	}
+	wg.Wait()
+	return res
+}
+
+func (r *GadgetToolRegistry) newWarmCacheTool() server.ServerTool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Fetches the info of all the gadget images concurrently so that it's cached and the first call " +
+			"of each gadget tool is faster, e.g. after deploying Inspektor Gadget. Reports how many images succeeded " +
+			"and failed, along with the errors."),
+		mcp.WithReadOnlyHintAnnotation(true),
+	}
+	tool := mcp.NewTool(
+		"warm-cache",
+		opts...,
+	)
+	return server.ServerTool{
+		Tool:    tool,
+		Handler: r.warmCacheHandler,
+	}
+}
+
+func (r *GadgetToolRegistry) warmCacheHandler(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	res := r.WarmInfoCache(ctx)
+	if res.Failed > 0 {
+		failed := make([]string, 0, len(res.Errors))
+		for image := range res.Errors {
+			failed = append(failed, image)
+		}
+		slices.Sort(failed)
+		log.WarnContext(ctx, "Failed to get the info of some gadget images", "failed", failed)
+	}
+	out, err := json.Marshal(res)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling warm cache result: %w", err)
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
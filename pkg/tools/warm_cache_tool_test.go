@@ -0,0 +1,43 @@
+// Copyright 2025 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestWarmInfoCache(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	r.images = []string{"trace_dns:latest", "trace_missing:latest"}
+	res := r.WarmInfoCache(context.Background())
+	if res.Images != 2 || res.Succeeded != 1 || res.Failed != 1 {
+		t.Errorf("expected 1 image to succeed and 1 to fail, got %+v", res)
+	}
+	if _, ok := res.Errors["trace_missing:latest"]; !ok || len(res.Errors) != 1 {
+		t.Errorf("expected the error of trace_missing:latest, got %v", res.Errors)
+	}
+
+	callRes, err := r.warmCacheHandler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, callRes); !strings.Contains(text, `"succeeded":1`) {
+		t.Errorf("expected the counts in the result, got %s", text)
+	}
+}